@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sync"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// connectionCache keeps long-lived clients such as Kafka producers across reconciles.
+// Clients are keyed by a hash of their settings, including credentials, so notifiers
+// with the same settings share one client and changed settings get a new one.
+// Clients no notifier asked for during a reconcile are closed by sweep.
+type connectionCache struct {
+	mu      sync.Mutex
+	round   uint64
+	entries map[string]*cachedConnection
+}
+
+type cachedConnection struct {
+	client  io.Closer
+	lastUse uint64
+}
+
+// get returns the client for settings, creating it if needed. Clients are created
+// without holding the lock, so a slow client does not hold up the others.
+func (c *connectionCache) get(kind string, settings any, create func() (io.Closer, error)) (io.Closer, error) {
+	key, err := connectionKey(kind, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if client, ok := c.lookup(key); ok {
+		return client, nil
+	}
+
+	client, err := create()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[key]; ok {
+		// created concurrently, keep the client already shared
+		entry.lastUse = c.round
+		if err := client.Close(); err != nil {
+			ctrl.Log.WithName("connections").Error(err, "failed to close duplicate connection")
+		}
+		return entry.client, nil
+	}
+	if c.entries == nil {
+		c.entries = make(map[string]*cachedConnection)
+	}
+	c.entries[key] = &cachedConnection{client: client, lastUse: c.round}
+	return client, nil
+}
+
+func (c *connectionCache) lookup(key string) (io.Closer, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry.lastUse = c.round
+	return entry.client, true
+}
+
+// sweep closes the clients that were not used since the previous sweep
+func (c *connectionCache) sweep() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if entry.lastUse < c.round {
+			if err := entry.client.Close(); err != nil {
+				ctrl.Log.WithName("connections").Error(err, "failed to close unused connection")
+			}
+			delete(c.entries, key)
+		}
+	}
+	c.round++
+}
+
+// closeAll closes every client, flushing what they buffered
+func (c *connectionCache) closeAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if err := entry.client.Close(); err != nil {
+			ctrl.Log.WithName("connections").Error(err, "failed to close connection")
+		}
+		delete(c.entries, key)
+	}
+}
+
+func connectionKey(kind string, settings any) (string, error) {
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(kind+"\x00"), raw...))
+	return hex.EncodeToString(sum[:]), nil
+}
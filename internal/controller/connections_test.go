@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+type fakeConnection struct {
+	closed int
+}
+
+func (c *fakeConnection) Close() error {
+	c.closed++
+	return nil
+}
+
+type fakeSettings struct {
+	Broker   string
+	Password string
+}
+
+func TestConnectionCacheGet(t *testing.T) {
+	var cache connectionCache
+	created := 0
+	create := func() (io.Closer, error) {
+		created++
+		return &fakeConnection{}, nil
+	}
+
+	first, err := cache.get("kafka", fakeSettings{Broker: "kafka:9092", Password: "secret"}, create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	same, err := cache.get("kafka", fakeSettings{Broker: "kafka:9092", Password: "secret"}, create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same != first || created != 1 {
+		t.Errorf("expected identical settings to share a client, created %d", created)
+	}
+
+	rotated, err := cache.get("kafka", fakeSettings{Broker: "kafka:9092", Password: "rotated"}, create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated == first || created != 2 {
+		t.Errorf("expected changed settings to get a new client, created %d", created)
+	}
+	other, err := cache.get("nats", fakeSettings{Broker: "kafka:9092", Password: "secret"}, create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == first || created != 3 {
+		t.Errorf("expected another kind to get its own client, created %d", created)
+	}
+
+	failing := func() (io.Closer, error) { return nil, errors.New("connection refused") }
+	if _, err := cache.get("amqp", fakeSettings{Broker: "rabbitmq:5672"}, failing); err == nil {
+		t.Error("expected the error creating the client")
+	}
+	if len(cache.entries) != 3 {
+		t.Errorf("expected failed clients not to be cached, got %d entries", len(cache.entries))
+	}
+}
+
+func TestConnectionCacheSweep(t *testing.T) {
+	var cache connectionCache
+	used := &fakeConnection{}
+	unused := &fakeConnection{}
+	for _, c := range []struct {
+		settings fakeSettings
+		client   *fakeConnection
+	}{
+		{fakeSettings{Broker: "used"}, used},
+		{fakeSettings{Broker: "unused"}, unused},
+	} {
+		if _, err := cache.get("mqtt", c.settings, func() (io.Closer, error) { return c.client, nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// clients created during the reconcile are kept by its sweep
+	cache.sweep()
+	if used.closed != 0 || unused.closed != 0 {
+		t.Fatal("expected the clients used in the reconcile to be kept")
+	}
+
+	if _, err := cache.get("mqtt", fakeSettings{Broker: "used"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	cache.sweep()
+	if used.closed != 0 || unused.closed != 1 {
+		t.Errorf("expected only the unused client to be closed, got %d and %d closes", used.closed, unused.closed)
+	}
+	if len(cache.entries) != 1 {
+		t.Errorf("expected the closed client to be removed, got %d entries", len(cache.entries))
+	}
+
+	cache.closeAll()
+	if used.closed != 1 || len(cache.entries) != 0 {
+		t.Errorf("expected every client to be closed, got %d closes and %d entries", used.closed, len(cache.entries))
+	}
+
+	recreated := 0
+	if _, err := cache.get("mqtt", fakeSettings{Broker: "used"}, func() (io.Closer, error) {
+		recreated++
+		return &fakeConnection{}, nil
+	}); err != nil || recreated != 1 {
+		t.Errorf("expected a new client after closing, got %v", err)
+	}
+}
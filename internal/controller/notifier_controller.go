@@ -26,27 +26,72 @@ import (
 	"golang.org/x/time/rate"
 	corev1 "k8s.io/api/core/v1"
 
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	monitoringv1 "github.com/example/notifier/api/v1"
+	"github.com/example/notifier/internal/digest"
+	"github.com/example/notifier/internal/schedule"
 
 	"github.com/example/notifier/pkg/publisher"
-	"github.com/example/notifier/pkg/publisher/slack"
+	"github.com/example/notifier/pkg/publisher/github"
+	"github.com/example/notifier/pkg/publisher/jira"
+	"github.com/example/notifier/pkg/publisher/servicenow"
+	"github.com/example/notifier/pkg/publisher/sms"
 )
 
 // ? should we move to config?
 // Define a rate limiter (allow max 1 event per second, with burst of 5)
 var eventRateLimiter = rate.NewLimiter(rate.Limit(1), 10)
 
+const (
+	// maxQueuedMessages bounds the messages held per Notifier while its schedule is closed
+	maxQueuedMessages = 500
+	// retryInterval is the requeue delay used when queued messages or a digest are overdue
+	retryInterval = 30 * time.Second
+	// publisherErrorPrefix starts the status message of notifiers whose publisher could not be created
+	publisherErrorPrefix = "Failed to create publisher: "
+	// scheduleErrorPrefix and digestErrorPrefix start the status message of notifiers skipped
+	// because of an invalid schedule or digest
+	scheduleErrorPrefix = "Invalid schedule: "
+	digestErrorPrefix   = "Invalid digest: "
+	// processedEventTTL is how long a processed event is suppressed at least, the cleanup
+	// running every processedEventTTL makes it up to twice as long
+	processedEventTTL = 5 * time.Minute
+)
+
 // NotifierReconciler reconciles a Notifier object
 type NotifierReconciler struct {
 	client.Client
-	Scheme          *runtime.Scheme
+	Scheme *runtime.Scheme
+	// APIReader reads Secrets and the metadata of event objects to find their owners without caching them,
+	// owners are not looked up and Secrets are read through the client if nil
+	APIReader client.Reader
+	// Clock used to evaluate schedules, defaults to the real clock
+	Clock           clock.Clock
 	processedEvents sync.Map
+
+	queueMu sync.Mutex
+	queued  map[types.NamespacedName][]publisher.Notification
+
+	// connections shared by the publishers of all notifiers
+	connections connectionCache
+	// githubTokens keeps GitHub App installation tokens across reconciles
+	githubTokens github.AppTokenCache
+	// serviceNowTokens keeps ServiceNow OAuth access tokens across reconciles
+	serviceNowTokens servicenow.TokenCache
+	// jiraSearches remembers the Jira instances without the /search/jql endpoint across reconciles
+	jiraSearches jira.SearchCache
+	// smsLimiters keeps the rate limit of each SMS recipient across notifiers and reconciles
+	smsLimiters sms.Limiters
 }
 
 type NotifierConfig struct {
@@ -54,10 +99,15 @@ type NotifierConfig struct {
 	EventTypes       map[string]bool
 	EventReasons     map[string]bool
 	EventObjectTypes map[string]bool
+	ResolveReasons   map[string]bool
 	MessageContains  []string
 }
 
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get
+// +kubebuilder:rbac:groups=apps,resources=replicasets;deployments;statefulsets;daemonsets,verbs=get
+// +kubebuilder:rbac:groups=batch,resources=jobs;cronjobs,verbs=get
 // +kubebuilder:rbac:groups=monitoring.example.com,resources=notifiers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=monitoring.example.com,resources=notifiers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=monitoring.example.com,resources=notifiers/finalizers,verbs=update
@@ -77,6 +127,7 @@ func (r *NotifierReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		notifiers       monitoringv1.NotifierList
 		eventList       corev1.EventList
 		processedEvents []string
+		owners          = ownerCache{}
 	)
 
 	log.Info("Starting reconciliation process")
@@ -91,24 +142,102 @@ func (r *NotifierReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
+	var requeueAfter time.Duration
 	for _, notifier := range notifiers.Items {
-		publisher, err := r.publisherFactory(ctx, &notifier)
+		originalStatus := notifier.Status.DeepCopy()
+
+		// a misconfigured or unreachable channel must not hold up the other notifiers
+		eventPublisher, err := r.publisherFactory(ctx, &notifier)
 		if err != nil {
-			log.Error(err, "failed to create publisher")
-			return ctrl.Result{}, err
+			r.skipNotifier(ctx, &notifier, originalStatus, publisherErrorPrefix, err)
+			continue
+		}
+
+		sched, err := schedule.New(notifier.Spec.Schedule, r.clock())
+		if err != nil {
+			r.skipNotifier(ctx, &notifier, originalStatus, scheduleErrorPrefix, err)
+			continue
+		}
+
+		digestOutsideOnly := sched != nil && sched.OutsideWindow == monitoringv1.OutsideWindowDigest
+		if digestOutsideOnly && notifier.Spec.Digest == nil {
+			err := fmt.Errorf("outsideWindow is %s but no digest is configured", sched.OutsideWindow)
+			r.skipNotifier(ctx, &notifier, originalStatus, scheduleErrorPrefix, err)
+			continue
+		}
+
+		// the fallback posts in the format of the notifier channel, which only webhook channels have
+		var fallbackPublisher publisher.Publisher
+		if sched != nil && sched.OutsideWindow == monitoringv1.OutsideWindowFallback {
+			if fallbackPublisher, err = r.webhookPublisher(&notifier, sched.FallbackWebhook); err != nil {
+				err = fmt.Errorf("outsideWindow %s is not supported: %w", sched.OutsideWindow, err)
+				r.skipNotifier(ctx, &notifier, originalStatus, scheduleErrorPrefix, err)
+				continue
+			}
+		}
+
+		if err := r.syncDigest(&notifier); err != nil {
+			r.skipNotifier(ctx, &notifier, originalStatus, digestErrorPrefix, err)
+			continue
+		}
+
+		for _, prefix := range []string{publisherErrorPrefix, scheduleErrorPrefix, digestErrorPrefix} {
+			if strings.HasPrefix(notifier.Status.StatusMessage, prefix) {
+				notifier.Status.StatusMessage = ""
+			}
+		}
+
+		if sched == nil || sched.Open() {
+			r.flushQueue(ctx, &notifier, eventPublisher)
 		}
 
+		resolver, canResolve := eventPublisher.(publisher.Resolver)
+
 		for _, k8sEvent := range eventList.Items {
 			stringEvents := fmt.Sprintf("%+v", k8sEvent)
+			if canResolve && r.shouldResolve(ctx, &notifier, k8sEvent) {
+				r.logVerbose(ctx, &notifier, "will resolve "+stringEvents)
+				r.resolve(ctx, &notifier, resolver, k8sEvent, owners)
+				continue
+			}
+
 			if r.shouldNotify(ctx, &notifier, k8sEvent) {
+				open := sched == nil || sched.Open()
+				if notifier.Spec.Digest != nil && (!digestOutsideOnly || !open) {
+					r.logVerbose(ctx, &notifier, "adding to digest "+stringEvents)
+					digest.Add(notifier.Status.Digest, k8sEvent, owners.ownerOf(ctx, r, &k8sEvent))
+					continue
+				}
+
+				notification := publisher.Notification{
+					Message:     r.constructEventMessage(ctx, &notifier, k8sEvent),
+					Event:       &k8sEvent,
+					ClusterName: clusterName(&notifier),
+					Owner:       owners.ownerOf(ctx, r, &k8sEvent),
+				}
+				target := eventPublisher
+
+				if !open {
+					switch sched.OutsideWindow {
+					case monitoringv1.OutsideWindowQueue:
+						r.logVerbose(ctx, &notifier, "outside schedule, queueing "+stringEvents)
+						r.enqueue(&notifier, notification)
+						continue
+					case monitoringv1.OutsideWindowFallback:
+						target = fallbackPublisher
+					default:
+						r.logVerbose(ctx, &notifier, "outside schedule, dropping "+stringEvents)
+						continue
+					}
+				}
+
 				if err := eventRateLimiter.Wait(ctx); err != nil {
 					log.Error(err, "Rate limiting failed")
 					continue
 				}
 
-				message := r.constructEventMessage(ctx, &notifier, k8sEvent)
 				r.logVerbose(ctx, &notifier, "will send "+stringEvents)
-				err := publisher.Send(ctx, message)
+				err := target.Send(ctx, notification)
 				if err != nil {
 					log.Error(err, "failed to send webhook")
 					continue
@@ -120,11 +249,24 @@ func (r *NotifierReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			}
 		}
 
+		if sched != nil && r.hasQueued(&notifier) {
+			if next := sched.NextOpen(); !next.IsZero() {
+				requeueAfter = earliest(requeueAfter, next.Sub(r.clock().Now()))
+			}
+		}
+
+		if notifier.Spec.Digest != nil {
+			r.postDigest(ctx, &notifier, eventPublisher)
+			requeueAfter = earliest(requeueAfter, notifier.Status.Digest.NextDigestTime.Sub(r.clock().Now()))
+		}
+
 		if len(processedEvents) > 0 {
 			notifier.Status.LastEventTime = &eventList.Items[len(eventList.Items)-1].LastTimestamp
 			notifier.Status.RecentEvents = processedEvents
 			notifier.Status.StatusMessage = fmt.Sprintf("Processed %d events", len(processedEvents))
+		}
 
+		if !equality.Semantic.DeepEqual(originalStatus, &notifier.Status) {
 			if err := r.Status().Update(ctx, &notifier); err != nil {
 				log.Error(err, "failed to update notifier status")
 				return ctrl.Result{}, err
@@ -132,13 +274,37 @@ func (r *NotifierReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 	}
 
+	r.connections.sweep()
+
 	log.Info("Reconciliation successful")
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// skipNotifier records in the status why notifier is not processed, prefix tells
+// which part of the configuration is at fault
+func (r *NotifierReconciler) skipNotifier(ctx context.Context, notifier *monitoringv1.Notifier, originalStatus *monitoringv1.NotifierStatus, prefix string, err error) {
+	log := log.FromContext(ctx)
+	log.Error(err, "skipping notifier", "notifier", notifier.Name, "reason", strings.TrimSuffix(prefix, ": "))
+
+	notifier.Status.StatusMessage = prefix + err.Error()
+	if !equality.Semantic.DeepEqual(originalStatus, &notifier.Status) {
+		if err := r.Status().Update(ctx, notifier); err != nil {
+			log.Error(err, "failed to update notifier status")
+		}
+	}
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NotifierReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.startCleanupRoutine()
+	// flush and close the shared connections on shutdown
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		r.connections.closeAll()
+		return nil
+	})); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&monitoringv1.Notifier{}).
 		Watches(
@@ -155,13 +321,140 @@ func (r *NotifierReconciler) logVerbose(ctx context.Context, notifier *monitorin
 	}
 }
 
-func (r *NotifierReconciler) publisherFactory(_ context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
-	switch notifier.Spec.Channel {
-	case monitoringv1.Slack:
-		return slack.NewSlackPublisher(notifier.Spec.Webhook), nil
-	default:
-		return nil, fmt.Errorf("unsupported publisher channel: %s", notifier.Spec.Channel)
+// syncDigest prepares the digest status for the notifier digest spec
+func (r *NotifierReconciler) syncDigest(notifier *monitoringv1.Notifier) error {
+	if notifier.Spec.Digest == nil {
+		notifier.Status.Digest = nil
+		return nil
 	}
+
+	if notifier.Status.Digest == nil {
+		notifier.Status.Digest = &monitoringv1.DigestStatus{PeriodStart: metav1.NewTime(r.clock().Now())}
+	}
+
+	// the next run is derived from the period start so schedule changes apply immediately
+	next, err := digest.NextRun(notifier.Spec.Digest, notifier.Status.Digest.PeriodStart.Time)
+	if err != nil {
+		return err
+	}
+	notifier.Status.Digest.NextDigestTime = &metav1.Time{Time: next}
+
+	return nil
+}
+
+// postDigest sends the accumulated digest once it is due and starts a new period.
+// A digest that fails to send is kept and retried on the next reconciliation.
+func (r *NotifierReconciler) postDigest(ctx context.Context, notifier *monitoringv1.Notifier, target publisher.Publisher) {
+	log := log.FromContext(ctx)
+	status := notifier.Status.Digest
+	now := r.clock().Now()
+
+	if now.Before(status.NextDigestTime.Time) {
+		return
+	}
+
+	if !digest.Empty(status) {
+		prefix := ""
+		if notifier.Spec.DefaultSettings != nil {
+			prefix = notifier.Spec.DefaultSettings.MessagePrefix
+		}
+		topOffenders := int(notifier.Spec.Digest.TopOffenders)
+		if topOffenders <= 0 {
+			topOffenders = 5
+		}
+
+		message := digest.Render(status, prefix, topOffenders, now)
+		if err := target.Send(ctx, publisher.Notification{Message: message, ClusterName: clusterName(notifier)}); err != nil {
+			log.Error(err, "failed to send digest")
+			return
+		}
+	}
+
+	next, err := digest.NextRun(notifier.Spec.Digest, now)
+	if err != nil {
+		log.Error(err, "invalid notifier digest", "notifier", notifier.Name)
+		return
+	}
+
+	notifier.Status.Digest = &monitoringv1.DigestStatus{
+		PeriodStart:    metav1.NewTime(now),
+		NextDigestTime: &metav1.Time{Time: next},
+	}
+}
+
+func earliest(current, wait time.Duration) time.Duration {
+	if wait <= 0 {
+		wait = retryInterval
+	}
+	if current == 0 || wait < current {
+		return wait
+	}
+	return current
+}
+
+func (r *NotifierReconciler) clock() clock.Clock {
+	if r.Clock == nil {
+		return clock.RealClock{}
+	}
+	return r.Clock
+}
+
+// enqueue holds a message until the notifier schedule opens again.
+// Queued messages live in memory and do not survive a restart.
+func (r *NotifierReconciler) enqueue(notifier *monitoringv1.Notifier, notification publisher.Notification) {
+	r.queueMu.Lock()
+	defer r.queueMu.Unlock()
+
+	if r.queued == nil {
+		r.queued = make(map[types.NamespacedName][]publisher.Notification)
+	}
+
+	key := client.ObjectKeyFromObject(notifier)
+	queue := append(r.queued[key], notification)
+	if len(queue) > maxQueuedMessages {
+		queue = queue[len(queue)-maxQueuedMessages:]
+	}
+	r.queued[key] = queue
+}
+
+func (r *NotifierReconciler) hasQueued(notifier *monitoringv1.Notifier) bool {
+	r.queueMu.Lock()
+	defer r.queueMu.Unlock()
+
+	return len(r.queued[client.ObjectKeyFromObject(notifier)]) > 0
+}
+
+// flushQueue sends the messages queued while the notifier schedule was closed.
+// Messages that fail to send are kept for the next reconciliation.
+func (r *NotifierReconciler) flushQueue(ctx context.Context, notifier *monitoringv1.Notifier, target publisher.Publisher) {
+	log := log.FromContext(ctx)
+	key := client.ObjectKeyFromObject(notifier)
+
+	r.queueMu.Lock()
+	queue := r.queued[key]
+	delete(r.queued, key)
+	r.queueMu.Unlock()
+
+	for i, notification := range queue {
+		if err := eventRateLimiter.Wait(ctx); err != nil {
+			log.Error(err, "Rate limiting failed")
+			r.requeue(key, queue[i:])
+			return
+		}
+
+		if err := target.Send(ctx, notification); err != nil {
+			log.Error(err, "failed to send queued message")
+			r.requeue(key, queue[i:])
+			return
+		}
+	}
+}
+
+func (r *NotifierReconciler) requeue(key types.NamespacedName, notifications []publisher.Notification) {
+	r.queueMu.Lock()
+	defer r.queueMu.Unlock()
+
+	r.queued[key] = append(notifications, r.queued[key]...)
 }
 
 func (r *NotifierReconciler) parseNotifierConfig(_ context.Context, notifier *monitoringv1.Notifier) *NotifierConfig {
@@ -170,6 +463,7 @@ func (r *NotifierReconciler) parseNotifierConfig(_ context.Context, notifier *mo
 		EventTypes:       toMap(notifier.Spec.EventTypes),
 		EventReasons:     toMap(notifier.Spec.EventReasons),
 		EventObjectTypes: toMap(notifier.Spec.EventObjectTypes),
+		ResolveReasons:   toMap(notifier.Spec.ResolveReasons),
 		MessageContains:  notifier.Spec.MessageContains,
 	}
 }
@@ -215,14 +509,57 @@ func (r *NotifierReconciler) shouldNotify(ctx context.Context, notifier *monitor
 	return true
 }
 
+// shouldResolve reports whether the event signals that a condition cleared for a monitored object
+func (r *NotifierReconciler) shouldResolve(ctx context.Context, notifier *monitoringv1.Notifier, event corev1.Event) bool {
+	config := r.parseNotifierConfig(ctx, notifier)
+
+	if !config.ResolveReasons[event.Reason] {
+		return false
+	}
+
+	if _, exists := r.processedEvents.Load(event.UID); exists {
+		return false
+	}
+
+	if !config.Namespaces[event.Namespace] {
+		return false
+	}
+
+	if len(config.EventObjectTypes) > 0 && !config.EventObjectTypes[event.InvolvedObject.Kind] {
+		return false
+	}
+
+	r.processedEvents.Store(event.UID, time.Now())
+	return true
+}
+
+func (r *NotifierReconciler) resolve(ctx context.Context, notifier *monitoringv1.Notifier, resolver publisher.Resolver, event corev1.Event, owners ownerCache) {
+	log := log.FromContext(ctx)
+
+	if err := eventRateLimiter.Wait(ctx); err != nil {
+		log.Error(err, "Rate limiting failed")
+		return
+	}
+
+	notification := publisher.Notification{
+		Message:     r.constructEventMessage(ctx, notifier, event),
+		Event:       &event,
+		ClusterName: clusterName(notifier),
+		Owner:       owners.ownerOf(ctx, r, &event),
+	}
+	if err := resolver.Resolve(ctx, notification); err != nil {
+		log.Error(err, "failed to resolve notification")
+	}
+}
+
 func (r *NotifierReconciler) startCleanupRoutine() {
 	// ? should this moved to config?
-	ticker := time.NewTicker(5 * time.Minute)
+	ticker := time.NewTicker(processedEventTTL)
 	go func() {
 		for range ticker.C {
 			now := time.Now()
 			r.processedEvents.Range(func(key, value interface{}) bool {
-				if t, ok := value.(time.Time); ok && now.Sub(t) > 5*time.Minute {
+				if t, ok := value.(time.Time); ok && now.Sub(t) > processedEventTTL {
 					r.processedEvents.Delete(key)
 				}
 				return true
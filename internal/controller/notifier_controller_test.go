@@ -2,13 +2,22 @@ package controller
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -109,3 +118,227 @@ var _ = Describe("Notifier Controller", func() {
 		})
 	})
 })
+
+var _ = Describe("Notifier Controller schedules", func() {
+	var (
+		ctx = context.Background()
+		// a window from 09:00 to 17:00 UTC, closed at the start of every test
+		closed = time.Date(2025, 3, 3, 20, 0, 0, 0, time.UTC)
+		open   = time.Date(2025, 3, 4, 9, 30, 0, 0, time.UTC)
+	)
+
+	window := []monitoringv1.TimeWindow{{Start: "09:00", End: "17:00"}}
+
+	It("should queue events outside the window and send them once it opens", func() {
+		webhook, server := newWebhookRecorder()
+		defer server.Close()
+		key := createTestNotifier(ctx, "schedule-queue", "ScheduleQueue", monitoringv1.NotifierSpec{
+			Channel:  monitoringv1.Slack,
+			Webhook:  server.URL,
+			Schedule: &monitoringv1.NotifierSchedule{Windows: window, OutsideWindow: monitoringv1.OutsideWindowQueue},
+		})
+		createTestEvent(ctx, "schedule-queue-event", "ScheduleQueue", "queued-pod", closed)
+
+		clk := clocktesting.NewFakeClock(closed)
+		reconciler := &NotifierReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), Clock: clk}
+		notifier := &monitoringv1.Notifier{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+
+		By("queueing the event while the window is closed")
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(webhook.posted()).To(BeEmpty())
+		Expect(reconciler.hasQueued(notifier)).To(BeTrue())
+		Expect(result.RequeueAfter).To(Equal(13 * time.Hour))
+
+		By("keeping the queued event when it fails to send")
+		clk.SetTime(open)
+		webhook.setFail(true)
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.hasQueued(notifier)).To(BeTrue())
+
+		By("sending the queued event once it can")
+		webhook.setFail(false)
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.hasQueued(notifier)).To(BeFalse())
+		Expect(webhook.posted()).To(HaveLen(1))
+		Expect(webhook.posted()[0]).To(ContainSubstring("queued-pod"))
+	})
+
+	It("should send events outside the window to the fallback webhook", func() {
+		webhook, server := newWebhookRecorder()
+		defer server.Close()
+		fallback, fallbackServer := newWebhookRecorder()
+		defer fallbackServer.Close()
+		key := createTestNotifier(ctx, "schedule-fallback", "ScheduleFallback", monitoringv1.NotifierSpec{
+			Channel: monitoringv1.Slack,
+			Webhook: server.URL,
+			Schedule: &monitoringv1.NotifierSchedule{
+				Windows:         window,
+				OutsideWindow:   monitoringv1.OutsideWindowFallback,
+				FallbackWebhook: fallbackServer.URL,
+			},
+		})
+		createTestEvent(ctx, "schedule-fallback-event", "ScheduleFallback", "fallback-pod", closed)
+
+		reconciler := &NotifierReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), Clock: clocktesting.NewFakeClock(closed)}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(webhook.posted()).To(BeEmpty())
+		Expect(fallback.posted()).To(HaveLen(1))
+		Expect(fallback.posted()[0]).To(ContainSubstring("fallback-pod"))
+	})
+
+	It("should report a fallback the channel does not support in the status", func() {
+		key := createTestNotifier(ctx, "schedule-unsupported-fallback", "ScheduleUnsupportedFallback", monitoringv1.NotifierSpec{
+			Channel:      monitoringv1.Alertmanager,
+			Alertmanager: &monitoringv1.AlertmanagerConfig{URL: "http://alertmanager.monitoring:9093"},
+			Schedule: &monitoringv1.NotifierSchedule{
+				Windows:         window,
+				OutsideWindow:   monitoringv1.OutsideWindowFallback,
+				FallbackWebhook: "http://fallback.example.com",
+			},
+		})
+
+		reconciler := &NotifierReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), Clock: clocktesting.NewFakeClock(closed)}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		notifier := &monitoringv1.Notifier{}
+		Expect(k8sClient.Get(ctx, key, notifier)).To(Succeed())
+		Expect(notifier.Status.StatusMessage).To(HavePrefix(scheduleErrorPrefix))
+		Expect(notifier.Status.StatusMessage).To(ContainSubstring("Fallback is not supported"))
+	})
+})
+
+var _ = Describe("Notifier Controller digests", func() {
+	ctx := context.Background()
+
+	It("should count the events of a workload and post them when the digest is due", func() {
+		webhook, server := newWebhookRecorder()
+		defer server.Close()
+		key := createTestNotifier(ctx, "digest", "DigestBackOff", monitoringv1.NotifierSpec{
+			Channel: monitoringv1.Slack,
+			Webhook: server.URL,
+			Digest:  &monitoringv1.NotifierDigest{Schedule: "0 9 * * *"},
+		})
+		start := time.Date(2025, 3, 3, 8, 0, 0, 0, time.UTC)
+		createTestEvent(ctx, "digest-event-a", "DigestBackOff", "digest-web-7d9f-a", start.Add(2*time.Minute))
+		createTestEvent(ctx, "digest-event-b", "DigestBackOff", "digest-web-7d9f-b", start.Add(time.Minute))
+
+		// the pods of the events belong to a Deployment
+		reader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "digest-web"}},
+			&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default", Name: "digest-web-7d9f",
+				OwnerReferences: controlledBy("apps/v1", "Deployment", "digest-web"),
+			}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default", Name: "digest-web-7d9f-a",
+				OwnerReferences: controlledBy("apps/v1", "ReplicaSet", "digest-web-7d9f"),
+			}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default", Name: "digest-web-7d9f-b",
+				OwnerReferences: controlledBy("apps/v1", "ReplicaSet", "digest-web-7d9f"),
+			}},
+		).Build()
+		clk := clocktesting.NewFakeClock(start)
+		reconciler := &NotifierReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), APIReader: reader, Clock: clk}
+
+		By("counting the events per owning workload")
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(webhook.posted()).To(BeEmpty())
+
+		notifier := &monitoringv1.Notifier{}
+		Expect(k8sClient.Get(ctx, key, notifier)).To(Succeed())
+		Expect(notifier.Status.Digest).NotTo(BeNil())
+		Expect(notifier.Status.Digest.PeriodStart.Time).To(BeTemporally("==", start))
+		Expect(notifier.Status.Digest.Entries).To(HaveLen(1))
+		entry := notifier.Status.Digest.Entries[0]
+		Expect(entry.Kind + "/" + entry.Name).To(Equal("Deployment/digest-web"))
+		Expect(entry.Count).To(BeEquivalentTo(2))
+
+		By("not counting the events again once they are no longer deduplicated")
+		reconciler.processedEvents.Clear()
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, notifier)).To(Succeed())
+		Expect(notifier.Status.Digest.Entries).To(HaveLen(1))
+		Expect(notifier.Status.Digest.Entries[0].Count).To(BeEquivalentTo(2))
+
+		By("posting the digest when it is due and starting a new period")
+		due := time.Date(2025, 3, 3, 9, 0, 30, 0, time.UTC)
+		clk.SetTime(due)
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(webhook.posted()).To(HaveLen(1))
+		Expect(webhook.posted()[0]).To(ContainSubstring("Deployment default/digest-web (DigestBackOff): 2"))
+
+		Expect(k8sClient.Get(ctx, key, notifier)).To(Succeed())
+		Expect(notifier.Status.Digest.Entries).To(BeEmpty())
+		Expect(notifier.Status.Digest.PeriodStart.Time).To(BeTemporally("==", due))
+	})
+})
+
+// createTestNotifier creates a notifier for the Warning events of reason in the default
+// namespace, deleted once the spec is done
+func createTestNotifier(ctx context.Context, name, reason string, spec monitoringv1.NotifierSpec) types.NamespacedName {
+	spec.Namespaces = []string{"default"}
+	spec.EventTypes = []string{"Warning"}
+	spec.EventReasons = []string{reason}
+	notifier := &monitoringv1.Notifier{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}, Spec: spec}
+	Expect(k8sClient.Create(ctx, notifier)).To(Succeed())
+	DeferCleanup(func() { Expect(k8sClient.Delete(ctx, notifier)).To(Succeed()) })
+	return client.ObjectKeyFromObject(notifier)
+}
+
+// createTestEvent creates a Warning event about a Pod last seen at the given time,
+// deleted once the spec is done
+func createTestEvent(ctx context.Context, name, reason, pod string, at time.Time) {
+	event := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: pod},
+		Reason:         reason,
+		Type:           "Warning",
+		Message:        "Back-off restarting failed container",
+		LastTimestamp:  metav1.NewTime(at),
+	}
+	Expect(k8sClient.Create(ctx, event)).To(Succeed())
+	DeferCleanup(func() { Expect(k8sClient.Delete(ctx, event)).To(Succeed()) })
+}
+
+// webhookRecorder records the bodies posted to a fake webhook, rejecting them while failing
+type webhookRecorder struct {
+	mu       sync.Mutex
+	messages []string
+	failing  bool
+}
+
+func newWebhookRecorder() (*webhookRecorder, *httptest.Server) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		if recorder.failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		recorder.messages = append(recorder.messages, string(body))
+	}))
+	return recorder, server
+}
+
+func (w *webhookRecorder) posted() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.messages...)
+}
+
+func (w *webhookRecorder) setFail(failing bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.failing = failing
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+// ownerLookupKinds are the kinds whose controller is looked up, matching the RBAC granted to the manager
+var ownerLookupKinds = map[string]schema.GroupVersion{
+	"Pod":        {Version: "v1"},
+	"ReplicaSet": {Group: "apps", Version: "v1"},
+	"Job":        {Group: "batch", Version: "v1"},
+}
+
+// annotatedKinds are the owner kinds whose annotations can be read, matching the RBAC granted to the manager
+var annotatedKinds = map[string]schema.GroupVersion{
+	"Pod":         {Version: "v1"},
+	"ReplicaSet":  {Group: "apps", Version: "v1"},
+	"Deployment":  {Group: "apps", Version: "v1"},
+	"StatefulSet": {Group: "apps", Version: "v1"},
+	"DaemonSet":   {Group: "apps", Version: "v1"},
+	"Job":         {Group: "batch", Version: "v1"},
+	"CronJob":     {Group: "batch", Version: "v1"},
+}
+
+// maxOwnerDepth bounds the walk up the controller references, Pod -> ReplicaSet -> Deployment needs two steps
+const maxOwnerDepth = 3
+
+// ownerOf returns the top-level controller of the event object in kind/name form,
+// e.g. the Deployment of a Pod. Objects without a known controller own themselves.
+func (r *NotifierReconciler) ownerOf(ctx context.Context, event *corev1.Event) string {
+	ref := event.InvolvedObject
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = event.Namespace
+	}
+	apiVersion, kind, name := ref.APIVersion, ref.Kind, ref.Name
+
+	// metadata is read uncached, watching every Pod of the cluster only for owners is not worth it
+	if r.APIReader == nil {
+		return kind + "/" + name
+	}
+
+	for depth := 0; depth < maxOwnerDepth; depth++ {
+		groupVersion, ok := ownerLookupKinds[kind]
+		if !ok {
+			break
+		}
+		// involved objects do not always carry an API version, but a kind of another group is a different resource
+		if apiVersion != "" {
+			if parsed, err := schema.ParseGroupVersion(apiVersion); err != nil || parsed.Group != groupVersion.Group {
+				break
+			}
+		}
+
+		object := &metav1.PartialObjectMetadata{}
+		object.SetGroupVersionKind(groupVersion.WithKind(kind))
+		if err := r.APIReader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, object); err != nil {
+			log.FromContext(ctx).V(1).Info("failed to look up owner", "kind", kind, "name", name, "error", err.Error())
+			break
+		}
+
+		controller := metav1.GetControllerOf(object)
+		if controller == nil {
+			break
+		}
+		apiVersion, kind, name = controller.APIVersion, controller.Kind, controller.Name
+	}
+
+	return kind + "/" + name
+}
+
+// ownerCache remembers the owners looked up during a reconcile by event UID, so that an
+// event notified on several channels is only looked up once
+type ownerCache map[types.UID]string
+
+// ownerOf returns the owner of the event object, looking it up on first use
+func (c ownerCache) ownerOf(ctx context.Context, r *NotifierReconciler, event *corev1.Event) string {
+	if event.UID == "" {
+		return r.ownerOf(ctx, event)
+	}
+	if owner, ok := c[event.UID]; ok {
+		return owner
+	}
+	owner := r.ownerOf(ctx, event)
+	c[event.UID] = owner
+	return owner
+}
+
+// ownerAnnotations returns the annotations of the owner of the notification
+// event, nil for digests, unknown kinds and owners that cannot be read
+func (r *NotifierReconciler) ownerAnnotations(ctx context.Context, notification publisher.Notification) map[string]string {
+	if r.APIReader == nil || notification.Event == nil {
+		return nil
+	}
+	kind, name, ok := strings.Cut(notification.Owner, "/")
+	groupVersion, known := annotatedKinds[kind]
+	if !ok || !known {
+		return nil
+	}
+	namespace := notification.Event.InvolvedObject.Namespace
+	if namespace == "" {
+		namespace = notification.Event.Namespace
+	}
+
+	object := &metav1.PartialObjectMetadata{}
+	object.SetGroupVersionKind(groupVersion.WithKind(kind))
+	if err := r.APIReader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, object); err != nil {
+		log.FromContext(ctx).V(1).Info("failed to read owner annotations", "kind", kind, "name", name, "error", err.Error())
+		return nil
+	}
+	return object.GetAnnotations()
+}
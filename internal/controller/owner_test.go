@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func controlledBy(apiVersion, kind, name string) []metav1.OwnerReference {
+	return []metav1.OwnerReference{{APIVersion: apiVersion, Kind: kind, Name: name, UID: types.UID("uid-" + name), Controller: ptr.To(true)}}
+}
+
+func newOwnerReconciler() *NotifierReconciler {
+	reader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "shop", Name: "cart",
+			Annotations: map[string]string{"github.com/repository": "shop/cart"},
+		}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "shop", Name: "cart-7f9c",
+			OwnerReferences: controlledBy("apps/v1", "Deployment", "cart"),
+		}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "shop", Name: "cart-7f9c-x2",
+			OwnerReferences: controlledBy("apps/v1", "ReplicaSet", "cart-7f9c"),
+		}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "debug"}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ops", Name: "backup-28950",
+			OwnerReferences: controlledBy("batch/v1", "CronJob", "backup"),
+		}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ops", Name: "backup-28950-q8z",
+			OwnerReferences: controlledBy("batch/v1", "Job", "backup-28950"),
+		}},
+	).Build()
+	return &NotifierReconciler{APIReader: reader}
+}
+
+func TestOwnerOf(t *testing.T) {
+	r := newOwnerReconciler()
+
+	for name, test := range map[string]struct {
+		event *corev1.Event
+		want  string
+	}{
+		"deployment pod": {
+			event: &corev1.Event{InvolvedObject: corev1.ObjectReference{Kind: "Pod", APIVersion: "v1", Namespace: "shop", Name: "cart-7f9c-x2"}},
+			want:  "Deployment/cart",
+		},
+		"namespace of the event": {
+			event: &corev1.Event{ObjectMeta: metav1.ObjectMeta{Namespace: "ops"}, InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "backup-28950-q8z"}},
+			want:  "CronJob/backup",
+		},
+		"replica set": {
+			event: &corev1.Event{InvolvedObject: corev1.ObjectReference{Kind: "ReplicaSet", APIVersion: "apps/v1", Namespace: "shop", Name: "cart-7f9c"}},
+			want:  "Deployment/cart",
+		},
+		"pod without controller": {
+			event: &corev1.Event{InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "debug"}},
+			want:  "Pod/debug",
+		},
+		"deleted pod": {
+			event: &corev1.Event{InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "cart-7f9c-gone"}},
+			want:  "Pod/cart-7f9c-gone",
+		},
+		"kind not looked up": {
+			event: &corev1.Event{InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "pi-1"}},
+			want:  "Node/pi-1",
+		},
+		"kind of another group": {
+			event: &corev1.Event{InvolvedObject: corev1.ObjectReference{Kind: "Pod", APIVersion: "metrics.k8s.io/v1beta1", Namespace: "shop", Name: "cart-7f9c-x2"}},
+			want:  "Pod/cart-7f9c-x2",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if owner := r.ownerOf(context.Background(), test.event); owner != test.want {
+				t.Errorf("expected owner %s, got %s", test.want, owner)
+			}
+		})
+	}
+
+	event := &corev1.Event{InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "cart-7f9c-x2"}}
+	if owner := (&NotifierReconciler{}).ownerOf(context.Background(), event); owner != "Pod/cart-7f9c-x2" {
+		t.Errorf("expected objects to own themselves without an API reader, got %s", owner)
+	}
+}
+
+func TestOwnerAnnotations(t *testing.T) {
+	r := newOwnerReconciler()
+	event := &corev1.Event{InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "cart-7f9c-x2"}}
+
+	for name, test := range map[string]struct {
+		notification publisher.Notification
+		want         string
+	}{
+		"annotated owner":   {notification: publisher.Notification{Event: event, Owner: "Deployment/cart"}, want: "shop/cart"},
+		"owner without any": {notification: publisher.Notification{Event: event, Owner: "ReplicaSet/cart-7f9c"}},
+		"missing owner":     {notification: publisher.Notification{Event: event, Owner: "Deployment/checkout"}},
+		"kind not readable": {notification: publisher.Notification{Event: event, Owner: "Rollout/cart"}},
+		"digest":            {notification: publisher.Notification{Message: "3 events"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			annotations := r.ownerAnnotations(context.Background(), test.notification)
+			if got := annotations["github.com/repository"]; got != test.want {
+				t.Errorf("expected annotation %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestOwnerCache(t *testing.T) {
+	r := newOwnerReconciler()
+	owners := ownerCache{}
+	event := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{UID: "e1"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "cart-7f9c-x2"},
+	}
+	if owner := owners.ownerOf(context.Background(), r, event); owner != "Deployment/cart" {
+		t.Fatalf("unexpected owner %s", owner)
+	}
+
+	// later lookups of the event in the reconcile are answered from the cache
+	r.APIReader = nil
+	if owner := owners.ownerOf(context.Background(), r, event); owner != "Deployment/cart" {
+		t.Errorf("expected the cached owner, got %s", owner)
+	}
+}
@@ -0,0 +1,923 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	monitoringv1 "github.com/example/notifier/api/v1"
+	"github.com/example/notifier/pkg/publisher"
+	"github.com/example/notifier/pkg/publisher/alertmanager"
+	"github.com/example/notifier/pkg/publisher/amqp"
+	"github.com/example/notifier/pkg/publisher/cloudevents"
+	"github.com/example/notifier/pkg/publisher/discord"
+	"github.com/example/notifier/pkg/publisher/elasticsearch"
+	"github.com/example/notifier/pkg/publisher/email"
+	"github.com/example/notifier/pkg/publisher/github"
+	"github.com/example/notifier/pkg/publisher/googlechat"
+	"github.com/example/notifier/pkg/publisher/gotify"
+	"github.com/example/notifier/pkg/publisher/jira"
+	"github.com/example/notifier/pkg/publisher/kafka"
+	"github.com/example/notifier/pkg/publisher/loki"
+	"github.com/example/notifier/pkg/publisher/mattermost"
+	"github.com/example/notifier/pkg/publisher/mqtt"
+	"github.com/example/notifier/pkg/publisher/nats"
+	"github.com/example/notifier/pkg/publisher/ntfy"
+	"github.com/example/notifier/pkg/publisher/opsgenie"
+	"github.com/example/notifier/pkg/publisher/otlp"
+	"github.com/example/notifier/pkg/publisher/pushover"
+	"github.com/example/notifier/pkg/publisher/rocketchat"
+	"github.com/example/notifier/pkg/publisher/servicenow"
+	"github.com/example/notifier/pkg/publisher/slack"
+	"github.com/example/notifier/pkg/publisher/sms"
+	"github.com/example/notifier/pkg/publisher/splunk"
+	"github.com/example/notifier/pkg/publisher/syslog"
+	"github.com/example/notifier/pkg/publisher/telegram"
+)
+
+func (r *NotifierReconciler) publisherFactory(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	switch notifier.Spec.Channel {
+	case monitoringv1.Slack:
+		if notifier.Spec.Slack != nil && notifier.Spec.Slack.BotTokenSecretRef != nil {
+			return r.slackWebAPIPublisher(ctx, notifier)
+		}
+	case monitoringv1.Opsgenie:
+		return r.opsgeniePublisher(ctx, notifier)
+	case monitoringv1.Email:
+		return r.emailPublisher(ctx, notifier)
+	case monitoringv1.Telegram:
+		return r.telegramPublisher(ctx, notifier)
+	case monitoringv1.Alertmanager:
+		return alertmanagerPublisher(notifier)
+	case monitoringv1.Kafka:
+		return r.kafkaPublisher(ctx, notifier)
+	case monitoringv1.NATS:
+		return r.natsPublisher(ctx, notifier)
+	case monitoringv1.AMQP:
+		return r.amqpPublisher(ctx, notifier)
+	case monitoringv1.MQTT:
+		return r.mqttPublisher(ctx, notifier)
+	case monitoringv1.Syslog:
+		return r.syslogPublisher(ctx, notifier)
+	case monitoringv1.SplunkHEC:
+		return r.splunkPublisher(ctx, notifier)
+	case monitoringv1.Elasticsearch:
+		return r.elasticsearchPublisher(ctx, notifier)
+	case monitoringv1.Loki:
+		return r.lokiPublisher(ctx, notifier)
+	case monitoringv1.OTLP:
+		return r.otlpPublisher(ctx, notifier)
+	case monitoringv1.Jira:
+		return r.jiraPublisher(ctx, notifier)
+	case monitoringv1.GitHub:
+		return r.githubPublisher(ctx, notifier)
+	case monitoringv1.ServiceNow:
+		return r.serviceNowPublisher(ctx, notifier)
+	case monitoringv1.SMS:
+		return r.smsPublisher(ctx, notifier)
+	case monitoringv1.Ntfy:
+		return r.ntfyPublisher(ctx, notifier)
+	case monitoringv1.Gotify:
+		return r.gotifyPublisher(ctx, notifier)
+	case monitoringv1.Pushover:
+		return r.pushoverPublisher(ctx, notifier)
+	}
+
+	return r.webhookPublisher(notifier, notifier.Spec.Webhook)
+}
+
+// webhookPublisher creates a publisher for the notifier channel posting to the given webhook
+func (r *NotifierReconciler) webhookPublisher(notifier *monitoringv1.Notifier, webhook string) (publisher.Publisher, error) {
+	if webhook == "" {
+		return nil, fmt.Errorf("webhook is required for channel %s", notifier.Spec.Channel)
+	}
+
+	switch notifier.Spec.Channel {
+	case monitoringv1.Slack:
+		links, err := slackLinks(notifier)
+		if err != nil {
+			return nil, err
+		}
+		return slack.NewSlackPublisher(webhook, links, slackLinkError(notifier)), nil
+	case monitoringv1.Discord:
+		return discord.NewDiscordPublisher(webhook), nil
+	case monitoringv1.Mattermost:
+		overrides := mattermost.Overrides{}
+		if config := notifier.Spec.Mattermost; config != nil {
+			overrides = mattermost.Overrides{Channel: config.Channel, Username: config.Username, IconURL: config.IconURL, IconEmoji: config.IconEmoji}
+		}
+		return mattermost.NewMattermostPublisher(webhook, overrides), nil
+	case monitoringv1.RocketChat:
+		overrides := rocketchat.Overrides{}
+		if config := notifier.Spec.RocketChat; config != nil {
+			overrides = rocketchat.Overrides{Channel: config.Channel, Username: config.Username, IconURL: config.IconURL, IconEmoji: config.IconEmoji}
+		}
+		return rocketchat.NewRocketChatPublisher(webhook, overrides), nil
+	case monitoringv1.GoogleChat:
+		return googlechat.NewGoogleChatPublisher(webhook), nil
+	case monitoringv1.CloudEvents:
+		mode := ""
+		if notifier.Spec.CloudEvents != nil {
+			mode = notifier.Spec.CloudEvents.ContentMode
+		}
+		return cloudevents.NewCloudEventsPublisher(webhook, mode), nil
+	default:
+		return nil, fmt.Errorf("unsupported publisher channel: %s", notifier.Spec.Channel)
+	}
+}
+
+func (r *NotifierReconciler) slackWebAPIPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.Slack
+	if config.ChannelID == "" {
+		return nil, fmt.Errorf("slack channelID is required when using a bot token")
+	}
+
+	token, err := r.secretValue(ctx, notifier.Namespace, config.BotTokenSecretRef)
+	if err != nil {
+		return nil, err
+	}
+	links, err := slackLinks(notifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return slack.NewWebAPIPublisher(token,
+		config.ChannelID,
+		newSlackThreadStore(notifier, r.clock()),
+		links,
+		slackLinkError(notifier),
+	), nil
+}
+
+// slackLinkError logs the links of notifier that fail to render, failing the send
+// would post the message again on every retry
+func slackLinkError(notifier *monitoringv1.Notifier) func(error) {
+	return func(err error) {
+		ctrl.Log.WithName("slack").Error(err, "failed to render link", "notifier", notifier.Name, "namespace", notifier.Namespace)
+	}
+}
+
+func (r *NotifierReconciler) opsgeniePublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.Opsgenie
+	if config == nil {
+		return nil, fmt.Errorf("opsgenie settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	apiKey, err := r.secretValue(ctx, notifier.Namespace, &config.APIKeySecretRef)
+	if err != nil {
+		return nil, err
+	}
+
+	priorities := make([]publisher.Rule[string], 0, len(config.Priorities))
+	for _, rule := range config.Priorities {
+		priorities = append(priorities, publisher.Rule[string]{Type: rule.Type, Reason: rule.Reason, Value: string(rule.Priority)})
+	}
+
+	return opsgenie.NewOpsgeniePublisher(opsgenie.Config{
+		APIURL:          config.APIURL,
+		APIKey:          apiKey,
+		Priorities:      priorities,
+		DefaultPriority: string(config.DefaultPriority),
+		Tags:            config.Tags,
+	}), nil
+}
+
+func (r *NotifierReconciler) emailPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.Email
+	if config == nil {
+		return nil, fmt.Errorf("email settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	var username, password string
+	if config.UsernameSecretRef != nil {
+		var err error
+		if username, err = r.secretValue(ctx, notifier.Namespace, config.UsernameSecretRef); err != nil {
+			return nil, err
+		}
+	}
+	if config.PasswordSecretRef != nil {
+		var err error
+		if password, err = r.secretValue(ctx, notifier.Namespace, config.PasswordSecretRef); err != nil {
+			return nil, err
+		}
+	}
+
+	return email.NewEmailPublisher(email.Config{
+		Host:     config.Host,
+		Port:     int(config.Port),
+		TLS:      config.TLS,
+		Username: username,
+		Password: password,
+		From:     config.From,
+		To:       config.To,
+		Cc:       config.Cc,
+		Subject:  config.Subject,
+	})
+}
+
+func (r *NotifierReconciler) telegramPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.Telegram
+	if config == nil {
+		return nil, fmt.Errorf("telegram settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	token, err := r.secretValue(ctx, notifier.Namespace, &config.BotTokenSecretRef)
+	if err != nil {
+		return nil, err
+	}
+	chatID, err := r.secretValue(ctx, notifier.Namespace, &config.ChatIDSecretRef)
+	if err != nil {
+		return nil, err
+	}
+
+	telegramPublisher := telegram.NewTelegramPublisher(strings.TrimSpace(token), strings.TrimSpace(chatID))
+	if config.APIURL != "" {
+		telegramPublisher.APIURL = config.APIURL
+	}
+	return telegramPublisher, nil
+}
+
+func alertmanagerPublisher(notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.Alertmanager
+	if config == nil {
+		return nil, fmt.Errorf("alertmanager settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	var quietPeriod time.Duration
+	if config.QuietPeriod != nil {
+		quietPeriod = config.QuietPeriod.Duration
+	}
+
+	return alertmanager.NewAlertmanagerPublisher(alertmanager.Config{
+		URL:         config.URL,
+		QuietPeriod: quietPeriod,
+		Labels:      config.Labels,
+	}), nil
+}
+
+func (r *NotifierReconciler) kafkaPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.Kafka
+	if config == nil {
+		return nil, fmt.Errorf("kafka settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	producerConfig := kafka.ProducerConfig{
+		Brokers:    config.Brokers,
+		Idempotent: config.Idempotent == nil || *config.Idempotent,
+		BatchSize:  int(config.BatchSize),
+	}
+	if config.FlushInterval != nil {
+		producerConfig.FlushInterval = config.FlushInterval.Duration
+	}
+
+	var err error
+	if producerConfig.TLS, err = r.tlsConfig(ctx, notifier.Namespace, config.TLS); err != nil {
+		return nil, err
+	}
+	if config.SASL != nil {
+		producerConfig.SASL = &kafka.SASL{Mechanism: config.SASL.Mechanism}
+		if producerConfig.SASL.Username, err = r.secretValue(ctx, notifier.Namespace, &config.SASL.UsernameSecretRef); err != nil {
+			return nil, err
+		}
+		if producerConfig.SASL.Password, err = r.secretValue(ctx, notifier.Namespace, &config.SASL.PasswordSecretRef); err != nil {
+			return nil, err
+		}
+	}
+
+	producer, err := r.connections.get("kafka", producerConfig, func() (io.Closer, error) {
+		return kafka.NewProducer(producerConfig, func(err error) {
+			ctrl.Log.WithName("kafka").Error(err, "failed to deliver message")
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return kafka.NewKafkaPublisher(producer.(*kafka.Producer), config.Topic, config.PartitionKey)
+}
+
+func (r *NotifierReconciler) natsPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.NATS
+	if config == nil {
+		return nil, fmt.Errorf("nats settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	connConfig := nats.ConnConfig{Servers: config.Servers}
+	var err error
+	if connConfig.TLS, err = r.tlsConfig(ctx, notifier.Namespace, config.TLS); err != nil {
+		return nil, err
+	}
+	switch {
+	case config.CredentialsSecretRef != nil:
+		if connConfig.Credentials, err = r.secretValue(ctx, notifier.Namespace, config.CredentialsSecretRef); err != nil {
+			return nil, err
+		}
+	case config.NKeySecretRef != nil:
+		if connConfig.NKeySeed, err = r.secretValue(ctx, notifier.Namespace, config.NKeySecretRef); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := r.connections.get("nats", connConfig, func() (io.Closer, error) {
+		return nats.Connect(connConfig, func(err error) {
+			ctrl.Log.WithName("nats").Error(err, "connection error")
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	publisherConfig := nats.Config{Subject: config.Subject, DigestSubject: config.DigestSubject}
+	if config.JetStream != nil {
+		publisherConfig.JetStream = true
+		if config.JetStream.AckWait != nil {
+			publisherConfig.AckWait = config.JetStream.AckWait.Duration
+		}
+	}
+	return nats.NewNATSPublisher(conn.(*nats.Conn), publisherConfig)
+}
+
+func (r *NotifierReconciler) amqpPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.AMQP
+	if config == nil {
+		return nil, fmt.Errorf("amqp settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	url, err := r.secretValue(ctx, notifier.Namespace, &config.URLSecretRef)
+	if err != nil {
+		return nil, err
+	}
+	connConfig := amqp.ConnConfig{URL: strings.TrimSpace(url)}
+	if connConfig.TLS, err = r.tlsConfig(ctx, notifier.Namespace, config.TLS); err != nil {
+		return nil, err
+	}
+
+	// notifiers publishing to the same broker share its connection
+	conn, err := r.connections.get("amqp", connConfig, func() (io.Closer, error) {
+		return amqp.NewConn(connConfig)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return amqp.NewAMQPPublisher(conn.(*amqp.Conn), config.Exchange, config.RoutingKey, config.DigestRoutingKey)
+}
+
+func (r *NotifierReconciler) mqttPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.MQTT
+	if config == nil {
+		return nil, fmt.Errorf("mqtt settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	clientConfig := mqtt.ClientConfig{
+		Broker:            config.Broker,
+		ClientID:          config.ClientID,
+		PersistentSession: config.PersistentSession == nil || *config.PersistentSession,
+	}
+	if clientConfig.ClientID == "" {
+		clientConfig.ClientID = fmt.Sprintf("k8s-event-notifier-%s-%s", notifier.Namespace, notifier.Name)
+	}
+
+	var err error
+	if clientConfig.TLS, err = r.tlsConfig(ctx, notifier.Namespace, config.TLS); err != nil {
+		return nil, err
+	}
+	if config.UsernameSecretRef != nil {
+		if clientConfig.Username, err = r.secretValue(ctx, notifier.Namespace, config.UsernameSecretRef); err != nil {
+			return nil, err
+		}
+	}
+	if config.PasswordSecretRef != nil {
+		if clientConfig.Password, err = r.secretValue(ctx, notifier.Namespace, config.PasswordSecretRef); err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := r.connections.get("mqtt", clientConfig, func() (io.Closer, error) {
+		return mqtt.Connect(clientConfig, func(err error) {
+			ctrl.Log.WithName("mqtt").Error(err, "connection lost, reconnecting", "broker", clientConfig.Broker)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mqtt.NewMQTTPublisher(client.(*mqtt.Client), mqtt.Config{
+		Topic:       config.Topic,
+		DigestTopic: config.DigestTopic,
+		QoS:         byte(config.QoS),
+		Retained:    config.Retained,
+	})
+}
+
+func (r *NotifierReconciler) syslogPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.Syslog
+	if config == nil {
+		return nil, fmt.Errorf("syslog settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	senderConfig := syslog.SenderConfig{Transport: config.Transport, Address: config.Address}
+	if senderConfig.Transport == "" {
+		senderConfig.Transport = syslog.TCP
+	}
+	var err error
+	if senderConfig.TLS, err = r.tlsConfig(ctx, notifier.Namespace, config.TLS); err != nil {
+		return nil, err
+	}
+
+	sender, err := r.connections.get("syslog", senderConfig, func() (io.Closer, error) {
+		return syslog.NewSender(senderConfig)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return syslog.NewSyslogPublisher(sender.(*syslog.Sender), int(config.Facility), config.StructuredDataID)
+}
+
+func (r *NotifierReconciler) splunkPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.SplunkHEC
+	if config == nil {
+		return nil, fmt.Errorf("splunkHEC settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	hecConfig := splunk.Config{
+		URL:        config.URL,
+		Index:      config.Index,
+		Source:     config.Source,
+		SourceType: config.SourceType,
+		BatchSize:  int(config.BatchSize),
+	}
+	if config.FlushInterval != nil {
+		hecConfig.FlushInterval = config.FlushInterval.Duration
+	}
+	token, err := r.secretValue(ctx, notifier.Namespace, &config.TokenSecretRef)
+	if err != nil {
+		return nil, err
+	}
+	hecConfig.Token = strings.TrimSpace(token)
+	if hecConfig.TLS, err = r.tlsConfig(ctx, notifier.Namespace, config.TLS); err != nil {
+		return nil, err
+	}
+
+	// the publisher batches in the background, so it lives as long as its settings
+	p, err := r.connections.get("splunkhec", hecConfig, func() (io.Closer, error) {
+		return splunk.NewHECPublisher(hecConfig, func(err error) {
+			ctrl.Log.WithName("splunkhec").Error(err, "failed to deliver events")
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p.(*splunk.HECPublisher), nil
+}
+
+func (r *NotifierReconciler) elasticsearchPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.Elasticsearch
+	if config == nil {
+		return nil, fmt.Errorf("elasticsearch settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	esConfig := elasticsearch.Config{
+		URL:       config.URL,
+		Index:     config.Index,
+		Pipeline:  config.Pipeline,
+		BatchSize: int(config.BatchSize),
+	}
+	if config.FlushInterval != nil {
+		esConfig.FlushInterval = config.FlushInterval.Duration
+	}
+
+	var err error
+	for _, item := range []struct {
+		ref   *monitoringv1.SecretKeyReference
+		value *string
+	}{
+		{config.UsernameSecretRef, &esConfig.Username},
+		{config.PasswordSecretRef, &esConfig.Password},
+		{config.APIKeySecretRef, &esConfig.APIKey},
+	} {
+		if item.ref == nil {
+			continue
+		}
+		if *item.value, err = r.secretValue(ctx, notifier.Namespace, item.ref); err != nil {
+			return nil, err
+		}
+	}
+	esConfig.APIKey = strings.TrimSpace(esConfig.APIKey)
+	if esConfig.TLS, err = r.tlsConfig(ctx, notifier.Namespace, config.TLS); err != nil {
+		return nil, err
+	}
+
+	p, err := r.connections.get("elasticsearch", esConfig, func() (io.Closer, error) {
+		return elasticsearch.NewElasticsearchPublisher(esConfig, func(err error) {
+			ctrl.Log.WithName("elasticsearch").Error(err, "failed to index documents")
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p.(*elasticsearch.ElasticsearchPublisher), nil
+}
+
+func (r *NotifierReconciler) lokiPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.Loki
+	if config == nil {
+		return nil, fmt.Errorf("loki settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	lokiConfig := loki.Config{
+		URL:       config.URL,
+		TenantID:  config.TenantID,
+		Encoding:  config.Encoding,
+		Labels:    config.Labels,
+		BatchSize: int(config.BatchSize),
+	}
+	if config.FlushInterval != nil {
+		lokiConfig.FlushInterval = config.FlushInterval.Duration
+	}
+
+	var err error
+	if config.UsernameSecretRef != nil {
+		if lokiConfig.Username, err = r.secretValue(ctx, notifier.Namespace, config.UsernameSecretRef); err != nil {
+			return nil, err
+		}
+	}
+	if config.PasswordSecretRef != nil {
+		if lokiConfig.Password, err = r.secretValue(ctx, notifier.Namespace, config.PasswordSecretRef); err != nil {
+			return nil, err
+		}
+	}
+	if lokiConfig.TLS, err = r.tlsConfig(ctx, notifier.Namespace, config.TLS); err != nil {
+		return nil, err
+	}
+
+	p, err := r.connections.get("loki", lokiConfig, func() (io.Closer, error) {
+		return loki.NewLokiPublisher(lokiConfig, func(err error) {
+			ctrl.Log.WithName("loki").Error(err, "failed to push entries")
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p.(*loki.LokiPublisher), nil
+}
+
+func (r *NotifierReconciler) otlpPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.OTLP
+	if config == nil {
+		return nil, fmt.Errorf("otlp settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	otlpConfig := otlp.Config{
+		Endpoint:  config.Endpoint,
+		Protocol:  config.Protocol,
+		Headers:   map[string]string{},
+		BatchSize: int(config.BatchSize),
+		QueueSize: int(config.QueueSize),
+	}
+	for name, value := range config.Headers {
+		otlpConfig.Headers[name] = value
+	}
+	if config.FlushInterval != nil {
+		otlpConfig.FlushInterval = config.FlushInterval.Duration
+	}
+	if retry := config.Retry; retry != nil {
+		otlpConfig.MaxAttempts = int(retry.MaxAttempts)
+		if retry.InitialInterval != nil {
+			otlpConfig.InitialBackoff = retry.InitialInterval.Duration
+		}
+	}
+
+	var err error
+	if config.AuthorizationSecretRef != nil {
+		authorization, err := r.secretValue(ctx, notifier.Namespace, config.AuthorizationSecretRef)
+		if err != nil {
+			return nil, err
+		}
+		otlpConfig.Headers["authorization"] = strings.TrimSpace(authorization)
+	}
+	if otlpConfig.TLS, err = r.tlsConfig(ctx, notifier.Namespace, config.TLS); err != nil {
+		return nil, err
+	}
+
+	p, err := r.connections.get("otlp", otlpConfig, func() (io.Closer, error) {
+		return otlp.NewOTLPPublisher(otlpConfig, func(err error) {
+			ctrl.Log.WithName("otlp").Error(err, "failed to export log records")
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p.(*otlp.OTLPPublisher), nil
+}
+
+func (r *NotifierReconciler) jiraPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.Jira
+	if config == nil {
+		return nil, fmt.Errorf("jira settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	token, err := r.secretValue(ctx, notifier.Namespace, &config.TokenSecretRef)
+	if err != nil {
+		return nil, err
+	}
+	var username string
+	if config.UsernameSecretRef != nil {
+		if username, err = r.secretValue(ctx, notifier.Namespace, config.UsernameSecretRef); err != nil {
+			return nil, err
+		}
+	}
+
+	return jira.NewJiraPublisher(jira.Config{
+		URL:               config.URL,
+		Username:          strings.TrimSpace(username),
+		Token:             strings.TrimSpace(token),
+		Project:           config.Project,
+		IssueType:         config.IssueType,
+		Summary:           config.Summary,
+		Description:       config.Description,
+		Labels:            config.Labels,
+		Fields:            config.Fields,
+		ResolveTransition: config.ResolveTransition,
+	}, &r.jiraSearches)
+}
+
+func (r *NotifierReconciler) githubPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.GitHub
+	if config == nil {
+		return nil, fmt.Errorf("github settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	githubConfig := github.Config{
+		BaseURL:          config.BaseURL,
+		Repository:       config.Repository,
+		DigestRepository: config.DigestRepository,
+		Title:            config.Title,
+		Body:             config.Body,
+		Labels:           config.Labels,
+	}
+	switch {
+	case config.App != nil:
+		privateKey, err := r.secretValue(ctx, notifier.Namespace, &config.App.PrivateKeySecretRef)
+		if err != nil {
+			return nil, err
+		}
+		githubConfig.App = &github.App{
+			ID:             config.App.AppID,
+			InstallationID: config.App.InstallationID,
+			PrivateKey:     privateKey,
+		}
+	case config.TokenSecretRef != nil:
+		token, err := r.secretValue(ctx, notifier.Namespace, config.TokenSecretRef)
+		if err != nil {
+			return nil, err
+		}
+		githubConfig.Token = strings.TrimSpace(token)
+	default:
+		return nil, fmt.Errorf("github tokenSecretRef or app is required for channel %s", notifier.Spec.Channel)
+	}
+
+	return github.NewGitHubPublisher(githubConfig, r.ownerAnnotations, &r.githubTokens)
+}
+
+func (r *NotifierReconciler) serviceNowPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.ServiceNow
+	if config == nil {
+		return nil, fmt.Errorf("servicenow settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	severities := make([]publisher.Rule[servicenow.Severity], 0, len(config.Severities))
+	for _, rule := range config.Severities {
+		severities = append(severities, publisher.Rule[servicenow.Severity]{
+			Type:   rule.Type,
+			Reason: rule.Reason,
+			Value:  servicenow.Severity{Urgency: int(rule.Urgency), Impact: int(rule.Impact)},
+		})
+	}
+	serviceNowConfig := servicenow.Config{
+		URL:             config.URL,
+		AssignmentGroup: config.AssignmentGroup,
+		Category:        config.Category,
+		CallerID:        config.CallerID,
+		Severities:      severities,
+		DefaultUrgency:  int(config.DefaultUrgency),
+		DefaultImpact:   int(config.DefaultImpact),
+	}
+	for _, item := range []struct {
+		ref   *monitoringv1.SecretKeyReference
+		value *string
+	}{
+		{config.UsernameSecretRef, &serviceNowConfig.Username},
+		{config.PasswordSecretRef, &serviceNowConfig.Password},
+	} {
+		if item.ref == nil {
+			continue
+		}
+		value, err := r.secretValue(ctx, notifier.Namespace, item.ref)
+		if err != nil {
+			return nil, err
+		}
+		*item.value = strings.TrimSpace(value)
+	}
+	if config.OAuth != nil {
+		clientID, err := r.secretValue(ctx, notifier.Namespace, &config.OAuth.ClientIDSecretRef)
+		if err != nil {
+			return nil, err
+		}
+		clientSecret, err := r.secretValue(ctx, notifier.Namespace, &config.OAuth.ClientSecretSecretRef)
+		if err != nil {
+			return nil, err
+		}
+		serviceNowConfig.OAuth = &servicenow.OAuth{ClientID: strings.TrimSpace(clientID), ClientSecret: strings.TrimSpace(clientSecret)}
+	} else if serviceNowConfig.Username == "" {
+		return nil, fmt.Errorf("servicenow usernameSecretRef or oauth is required for channel %s", notifier.Spec.Channel)
+	}
+
+	return servicenow.NewServiceNowPublisher(serviceNowConfig, &r.serviceNowTokens), nil
+}
+
+func (r *NotifierReconciler) smsPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.SMS
+	if config == nil {
+		return nil, fmt.Errorf("sms settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	authToken, err := r.secretValue(ctx, notifier.Namespace, &config.AuthTokenSecretRef)
+	if err != nil {
+		return nil, err
+	}
+	smsConfig := sms.Config{
+		BaseURL:             config.BaseURL,
+		AccountSID:          config.AccountSID,
+		AuthToken:           strings.TrimSpace(authToken),
+		From:                config.From,
+		MessagingServiceSID: config.MessagingServiceSID,
+		Recipients:          config.Recipients,
+	}
+	if limit := config.RateLimit; limit != nil {
+		smsConfig.MaxMessages = int(limit.MaxMessages)
+		if limit.Period != nil {
+			smsConfig.Period = limit.Period.Duration
+		}
+	}
+
+	return sms.NewSMSPublisher(smsConfig, &r.smsLimiters)
+}
+
+func (r *NotifierReconciler) ntfyPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.Ntfy
+	if config == nil {
+		return nil, fmt.Errorf("ntfy settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	var token string
+	if config.TokenSecretRef != nil {
+		value, err := r.secretValue(ctx, notifier.Namespace, config.TokenSecretRef)
+		if err != nil {
+			return nil, err
+		}
+		token = strings.TrimSpace(value)
+	}
+
+	return ntfy.NewNtfyPublisher(ntfy.Config{
+		ServerURL:       config.ServerURL,
+		Topic:           config.Topic,
+		Token:           token,
+		Tags:            config.Tags,
+		Priorities:      pushPriorities(config.Priorities),
+		DefaultPriority: int(config.DefaultPriority),
+		ClickURL:        config.ClickURL,
+	})
+}
+
+func (r *NotifierReconciler) gotifyPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.Gotify
+	if config == nil {
+		return nil, fmt.Errorf("gotify settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	token, err := r.secretValue(ctx, notifier.Namespace, &config.TokenSecretRef)
+	if err != nil {
+		return nil, err
+	}
+	defaultPriority := gotify.DefaultPriority
+	if config.DefaultPriority != nil {
+		defaultPriority = int(*config.DefaultPriority)
+	}
+
+	return gotify.NewGotifyPublisher(gotify.Config{
+		URL:             config.URL,
+		Token:           strings.TrimSpace(token),
+		Priorities:      pushPriorities(config.Priorities),
+		DefaultPriority: defaultPriority,
+		ClickURL:        config.ClickURL,
+	})
+}
+
+func (r *NotifierReconciler) pushoverPublisher(ctx context.Context, notifier *monitoringv1.Notifier) (publisher.Publisher, error) {
+	config := notifier.Spec.Pushover
+	if config == nil {
+		return nil, fmt.Errorf("pushover settings are required for channel %s", notifier.Spec.Channel)
+	}
+
+	token, err := r.secretValue(ctx, notifier.Namespace, &config.TokenSecretRef)
+	if err != nil {
+		return nil, err
+	}
+	userKey, err := r.secretValue(ctx, notifier.Namespace, &config.UserKeySecretRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return pushover.NewPushoverPublisher(pushover.Config{
+		Token:           strings.TrimSpace(token),
+		UserKey:         strings.TrimSpace(userKey),
+		Device:          config.Device,
+		Sound:           config.Sound,
+		Priorities:      pushPriorities(config.Priorities),
+		DefaultPriority: int(config.DefaultPriority),
+		ClickURL:        config.ClickURL,
+	})
+}
+
+func pushPriorities(rules []monitoringv1.PushPriorityRule) []publisher.Rule[int] {
+	priorities := make([]publisher.Rule[int], 0, len(rules))
+	for _, rule := range rules {
+		priorities = append(priorities, publisher.Rule[int]{Type: rule.Type, Reason: rule.Reason, Value: int(rule.Priority)})
+	}
+	return priorities
+}
+
+// tlsConfig reads the TLS material referenced by config, nil if TLS is not configured
+func (r *NotifierReconciler) tlsConfig(ctx context.Context, namespace string, config *monitoringv1.TLSConfig) (*publisher.TLSConfig, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &publisher.TLSConfig{InsecureSkipVerify: config.InsecureSkipVerify}
+	for _, item := range []struct {
+		ref   *monitoringv1.SecretKeyReference
+		value *string
+	}{
+		{config.CASecretRef, &tlsConfig.CA},
+		{config.CertSecretRef, &tlsConfig.Cert},
+		{config.KeySecretRef, &tlsConfig.Key},
+	} {
+		if item.ref == nil {
+			continue
+		}
+		value, err := r.secretValue(ctx, namespace, item.ref)
+		if err != nil {
+			return nil, err
+		}
+		*item.value = value
+	}
+	return tlsConfig, nil
+}
+
+// secretValue reads a key of a Secret in the given namespace
+func (r *NotifierReconciler) secretValue(ctx context.Context, namespace string, ref *monitoringv1.SecretKeyReference) (string, error) {
+	// reading through the cache would watch every Secret in the cluster, they are fetched when needed instead
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	var secret corev1.Secret
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, &secret); err != nil {
+		return "", fmt.Errorf("failed to get secret %s/%s: %w", namespace, ref.Name, err)
+	}
+
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s/%s", ref.Key, namespace, ref.Name)
+	}
+	return string(value), nil
+}
+
+func slackLinks(notifier *monitoringv1.Notifier) ([]slack.Link, error) {
+	if notifier.Spec.Slack == nil {
+		return nil, nil
+	}
+
+	links := make([]slack.Link, 0, len(notifier.Spec.Slack.Links))
+	for _, link := range notifier.Spec.Slack.Links {
+		parsed, err := slack.NewLink(link.Text, link.URL)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, parsed)
+	}
+	return links, nil
+}
+
+func clusterName(notifier *monitoringv1.Notifier) string {
+	if notifier.Spec.DefaultSettings == nil {
+		return ""
+	}
+	return notifier.Spec.DefaultSettings.ClusterName
+}
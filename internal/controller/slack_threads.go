@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+
+	monitoringv1 "github.com/example/notifier/api/v1"
+	"github.com/example/notifier/pkg/publisher/slack"
+)
+
+const (
+	// slackThreadTTL is how long a quiet thread keeps collecting replies
+	// before the next event for the object starts a new one
+	slackThreadTTL = 24 * time.Hour
+	// maxSlackThreads bounds the threads kept in the Notifier status
+	maxSlackThreads = 200
+)
+
+// slackThreadStore keeps Slack threads in the Notifier status so they survive restarts
+type slackThreadStore struct {
+	notifier *monitoringv1.Notifier
+	clock    clock.PassiveClock
+}
+
+func newSlackThreadStore(notifier *monitoringv1.Notifier, clk clock.PassiveClock) *slackThreadStore {
+	store := &slackThreadStore{notifier: notifier, clock: clk}
+	store.prune()
+	return store
+}
+
+func (s *slackThreadStore) Get(key string) (slack.Thread, bool) {
+	for _, thread := range s.notifier.Status.SlackThreads {
+		if thread.Key == key {
+			return slack.Thread{Channel: thread.Channel, TS: thread.TS, Count: int(thread.Count)}, true
+		}
+	}
+	return slack.Thread{}, false
+}
+
+func (s *slackThreadStore) Put(key string, thread slack.Thread) {
+	record := monitoringv1.SlackThread{
+		Key:      key,
+		Channel:  thread.Channel,
+		TS:       thread.TS,
+		Count:    int32(thread.Count),
+		LastSeen: metav1.NewTime(s.clock.Now()),
+	}
+
+	threads := s.notifier.Status.SlackThreads
+	for i := range threads {
+		if threads[i].Key == key {
+			threads[i] = record
+			return
+		}
+	}
+	s.notifier.Status.SlackThreads = append(threads, record)
+	s.prune()
+}
+
+func (s *slackThreadStore) Delete(key string) {
+	threads := s.notifier.Status.SlackThreads[:0]
+	for _, thread := range s.notifier.Status.SlackThreads {
+		if thread.Key != key {
+			threads = append(threads, thread)
+		}
+	}
+	s.notifier.Status.SlackThreads = threads
+}
+
+// prune drops expired threads and keeps only the most recently used ones
+func (s *slackThreadStore) prune() {
+	if len(s.notifier.Status.SlackThreads) == 0 {
+		return
+	}
+
+	cutoff := s.clock.Now().Add(-slackThreadTTL)
+	threads := make([]monitoringv1.SlackThread, 0, len(s.notifier.Status.SlackThreads))
+	for _, thread := range s.notifier.Status.SlackThreads {
+		if thread.LastSeen.Time.After(cutoff) {
+			threads = append(threads, thread)
+		}
+	}
+
+	if len(threads) > maxSlackThreads {
+		sort.SliceStable(threads, func(i, j int) bool {
+			return threads[i].LastSeen.After(threads[j].LastSeen.Time)
+		})
+		threads = threads[:maxSlackThreads]
+	}
+
+	s.notifier.Status.SlackThreads = threads
+}
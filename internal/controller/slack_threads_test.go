@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+
+	monitoringv1 "github.com/example/notifier/api/v1"
+	"github.com/example/notifier/pkg/publisher/slack"
+)
+
+func TestSlackThreadStore(t *testing.T) {
+	clk := clocktesting.NewFakePassiveClock(time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC))
+	notifier := &monitoringv1.Notifier{}
+	store := newSlackThreadStore(notifier, clk)
+
+	store.Put("shop/Pod/cart-0", slack.Thread{Channel: "C1", TS: "1.1", Count: 1})
+	store.Put("shop/Pod/cart-0", slack.Thread{Channel: "C1", TS: "1.1", Count: 2})
+	if thread, ok := store.Get("shop/Pod/cart-0"); !ok || thread.Count != 2 || len(notifier.Status.SlackThreads) != 1 {
+		t.Errorf("expected the thread to be updated in the status, got %+v", notifier.Status.SlackThreads)
+	}
+
+	store.Delete("shop/Pod/cart-0")
+	if _, ok := store.Get("shop/Pod/cart-0"); ok {
+		t.Error("expected the thread to be deleted")
+	}
+
+	// threads quiet for longer than the TTL are dropped when the store is created
+	store.Put("shop/Pod/cart-1", slack.Thread{Channel: "C1", TS: "2.1", Count: 1})
+	clk.SetTime(clk.Now().Add(slackThreadTTL + time.Minute))
+	store = newSlackThreadStore(notifier, clk)
+	if _, ok := store.Get("shop/Pod/cart-1"); ok {
+		t.Error("expected the expired thread to be pruned")
+	}
+
+	for i := range maxSlackThreads + 1 {
+		clk.SetTime(clk.Now().Add(time.Second))
+		store.Put(fmt.Sprintf("shop/Pod/web-%d", i), slack.Thread{Channel: "C1", TS: fmt.Sprint(i), Count: 1})
+	}
+	if n := len(notifier.Status.SlackThreads); n != maxSlackThreads {
+		t.Errorf("expected at most %d threads, got %d", maxSlackThreads, n)
+	}
+	if _, ok := store.Get("shop/Pod/web-0"); ok {
+		t.Error("expected the least recently used thread to be dropped")
+	}
+}
@@ -0,0 +1,219 @@
+package digest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	monitoringv1 "github.com/example/notifier/api/v1"
+	"github.com/example/notifier/pkg/publisher"
+)
+
+const (
+	// maxEntries bounds the entries kept in the Notifier status
+	maxEntries = 200
+	// maxObjects bounds the involved objects remembered per entry. Events of further
+	// objects are only counted if newer than the last event of the entry.
+	maxObjects = 20
+)
+
+// NextRun returns the first time after the given time that matches the digest schedule.
+func NextRun(spec *monitoringv1.NotifierDigest, after time.Time) (time.Time, error) {
+	expr := spec.Schedule
+	if spec.TimeZone != "" {
+		expr = fmt.Sprintf("CRON_TZ=%s %s", spec.TimeZone, expr)
+	}
+
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid digest schedule %q: %w", expr, err)
+	}
+
+	next := schedule.Next(after)
+	if next.IsZero() {
+		return next, fmt.Errorf("digest schedule %q never fires", expr)
+	}
+	return next, nil
+}
+
+// Add counts the event into the digest entry of its owner, given in kind/name form,
+// and reports whether the digest changed. Without an owner the event is counted for
+// its involved object. Events that are not newer than the last one counted for the
+// same involved object and reason are ignored, so events listed again are not counted twice.
+func Add(status *monitoringv1.DigestStatus, event corev1.Event, owner string) bool {
+	seen := publisher.EventTime(&event, event.CreationTimestamp.Time)
+	if seen.Before(status.PeriodStart.Time) {
+		return false
+	}
+	kind, name, ok := strings.Cut(owner, "/")
+	if !ok {
+		kind, name = event.InvolvedObject.Kind, event.InvolvedObject.Name
+	}
+
+	for i := range status.Entries {
+		entry := &status.Entries[i]
+		if entry.Namespace != event.Namespace ||
+			entry.Reason != event.Reason ||
+			entry.Kind != kind ||
+			entry.Name != name {
+			continue
+		}
+
+		if !countObject(entry, event.InvolvedObject.Name, seen) {
+			return false
+		}
+		entry.Count++
+		if seen.Before(entry.FirstSeen.Time) {
+			entry.FirstSeen = metav1.NewTime(seen)
+		}
+		if seen.After(entry.LastSeen.Time) {
+			entry.LastSeen = metav1.NewTime(seen)
+		}
+		return true
+	}
+
+	if len(status.Entries) >= maxEntries {
+		status.Overflow++
+		return true
+	}
+
+	status.Entries = append(status.Entries, monitoringv1.DigestEntry{
+		Namespace: event.Namespace,
+		Reason:    event.Reason,
+		Kind:      kind,
+		Name:      name,
+		Count:     1,
+		FirstSeen: metav1.NewTime(seen),
+		LastSeen:  metav1.NewTime(seen),
+		Objects:   []monitoringv1.DigestObject{{Name: event.InvolvedObject.Name, LastSeen: metav1.NewTime(seen)}},
+	})
+	return true
+}
+
+// countObject records seen as the last event of the named involved object of the entry,
+// reporting false if an event at least as recent was counted for the object already.
+func countObject(entry *monitoringv1.DigestEntry, name string, seen time.Time) bool {
+	oldest := -1
+	for i := range entry.Objects {
+		object := &entry.Objects[i]
+		if object.Name == name {
+			if !seen.After(object.LastSeen.Time) {
+				return false
+			}
+			object.LastSeen = metav1.NewTime(seen)
+			return true
+		}
+		if oldest < 0 || object.LastSeen.Time.Before(entry.Objects[oldest].LastSeen.Time) {
+			oldest = i
+		}
+	}
+
+	if len(entry.Objects) < maxObjects {
+		entry.Objects = append(entry.Objects, monitoringv1.DigestObject{Name: name, LastSeen: metav1.NewTime(seen)})
+		return true
+	}
+	// the object may have been forgotten, fall back to the last event of the entry
+	if !seen.After(entry.LastSeen.Time) {
+		return false
+	}
+	entry.Objects[oldest] = monitoringv1.DigestObject{Name: name, LastSeen: metav1.NewTime(seen)}
+	return true
+}
+
+// Empty reports whether nothing was accumulated in the digest.
+func Empty(status *monitoringv1.DigestStatus) bool {
+	return len(status.Entries) == 0 && status.Overflow == 0
+}
+
+// Render builds the summary message for the accumulated digest.
+func Render(status *monitoringv1.DigestStatus, prefix string, topOffenders int, now time.Time) string {
+	var (
+		total       = status.Overflow
+		byNamespace = map[string]int32{}
+		byReason    = map[string]int32{}
+		firstSeen   time.Time
+		lastSeen    time.Time
+	)
+
+	for _, entry := range status.Entries {
+		total += entry.Count
+		byNamespace[entry.Namespace] += entry.Count
+		byReason[entry.Reason] += entry.Count
+
+		if firstSeen.IsZero() || entry.FirstSeen.Time.Before(firstSeen) {
+			firstSeen = entry.FirstSeen.Time
+		}
+		if entry.LastSeen.After(lastSeen) {
+			lastSeen = entry.LastSeen.Time
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s*\n*Event digest* from %s to %s: *%d* events\n",
+		prefix,
+		status.PeriodStart.UTC().Format(time.RFC3339),
+		now.UTC().Format(time.RFC3339),
+		total,
+	)
+	if !firstSeen.IsZero() {
+		fmt.Fprintf(&b, "*First seen:* %s\n*Last seen:* %s\n",
+			firstSeen.UTC().Format(time.RFC3339),
+			lastSeen.UTC().Format(time.RFC3339),
+		)
+	}
+	fmt.Fprintf(&b, "*By namespace:* %s\n", formatCounts(byNamespace))
+	fmt.Fprintf(&b, "*By reason:* %s\n", formatCounts(byReason))
+
+	offenders := append([]monitoringv1.DigestEntry(nil), status.Entries...)
+	sort.SliceStable(offenders, func(i, j int) bool {
+		if offenders[i].Count != offenders[j].Count {
+			return offenders[i].Count > offenders[j].Count
+		}
+		return offenders[i].LastSeen.After(offenders[j].LastSeen.Time)
+	})
+	if len(offenders) > topOffenders {
+		offenders = offenders[:topOffenders]
+	}
+
+	b.WriteString("*Top offenders:*")
+	for _, entry := range offenders {
+		fmt.Fprintf(&b, "\n• %s %s/%s (%s): %d, first %s, last %s",
+			entry.Kind,
+			entry.Namespace,
+			entry.Name,
+			entry.Reason,
+			entry.Count,
+			entry.FirstSeen.UTC().Format(time.RFC3339),
+			entry.LastSeen.UTC().Format(time.RFC3339),
+		)
+	}
+	if status.Overflow > 0 {
+		fmt.Fprintf(&b, "\n_%d further events not itemised_", status.Overflow)
+	}
+
+	return b.String()
+}
+
+func formatCounts(counts map[string]int32) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s (%d)", k, counts[k]))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,146 @@
+package digest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	monitoringv1 "github.com/example/notifier/api/v1"
+)
+
+func newEvent(namespace, reason, name string, at time.Time) corev1.Event {
+	return corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: namespace},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: namespace, Name: name},
+		Reason:         reason,
+		LastTimestamp:  metav1.NewTime(at),
+	}
+}
+
+func TestAdd(t *testing.T) {
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	status := &monitoringv1.DigestStatus{PeriodStart: metav1.NewTime(start)}
+
+	if Add(status, newEvent("default", "Pulled", "web", start.Add(-time.Minute)), "") {
+		t.Error("event before the period start should be ignored")
+	}
+	if !Add(status, newEvent("default", "Pulled", "web", start.Add(time.Minute)), "") {
+		t.Error("first event should be counted")
+	}
+	if !Add(status, newEvent("default", "Pulled", "web", start.Add(2*time.Minute)), "") {
+		t.Error("newer event should be counted")
+	}
+	if Add(status, newEvent("default", "Pulled", "web", start.Add(2*time.Minute)), "") {
+		t.Error("event seen again should not be counted twice")
+	}
+	Add(status, newEvent("kube-system", "Pulled", "dns", start.Add(3*time.Minute)), "")
+
+	if len(status.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(status.Entries))
+	}
+	web := status.Entries[0]
+	if web.Count != 2 || !web.FirstSeen.Time.Equal(start.Add(time.Minute)) || !web.LastSeen.Time.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("unexpected entry %+v", web)
+	}
+}
+
+func TestAddOwner(t *testing.T) {
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	status := &monitoringv1.DigestStatus{PeriodStart: metav1.NewTime(start)}
+
+	Add(status, newEvent("default", "BackOff", "web-7d9f-a", start.Add(2*time.Minute)), "Deployment/web")
+	if !Add(status, newEvent("default", "BackOff", "web-7d9f-b", start.Add(time.Minute)), "Deployment/web") {
+		t.Error("older event of another pod of the workload should be counted")
+	}
+	if Add(status, newEvent("default", "BackOff", "web-7d9f-a", start.Add(2*time.Minute)), "Deployment/web") {
+		t.Error("event seen again should not be counted twice")
+	}
+
+	if len(status.Entries) != 1 {
+		t.Fatalf("expected 1 entry, got %+v", status.Entries)
+	}
+	web := status.Entries[0]
+	if web.Kind != "Deployment" || web.Name != "web" || web.Count != 2 ||
+		!web.FirstSeen.Time.Equal(start.Add(time.Minute)) || !web.LastSeen.Time.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("unexpected entry %+v", web)
+	}
+}
+
+func TestAddManyObjects(t *testing.T) {
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	status := &monitoringv1.DigestStatus{PeriodStart: metav1.NewTime(start)}
+
+	for i := 0; i < maxObjects+1; i++ {
+		Add(status, newEvent("default", "BackOff", fmt.Sprintf("pod-%d", i), start.Add(time.Duration(i+1)*time.Second)), "Deployment/web")
+	}
+	if Add(status, newEvent("default", "BackOff", "pod-0", start.Add(time.Second)), "Deployment/web") {
+		t.Error("event of a forgotten object should not be counted twice")
+	}
+
+	if len(status.Entries) != 1 || status.Entries[0].Count != maxObjects+1 || len(status.Entries[0].Objects) != maxObjects {
+		t.Errorf("unexpected entries %+v", status.Entries)
+	}
+}
+
+func TestAddOverflow(t *testing.T) {
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	status := &monitoringv1.DigestStatus{PeriodStart: metav1.NewTime(start)}
+
+	for i := 0; i < maxEntries+3; i++ {
+		Add(status, newEvent("default", "Pulled", fmt.Sprintf("pod-%d", i), start.Add(time.Second)), "")
+	}
+
+	if len(status.Entries) != maxEntries || status.Overflow != 3 {
+		t.Errorf("expected %d entries and 3 overflow, got %d and %d", maxEntries, len(status.Entries), status.Overflow)
+	}
+}
+
+func TestRender(t *testing.T) {
+	start := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	status := &monitoringv1.DigestStatus{PeriodStart: metav1.NewTime(start)}
+	for i := 0; i < 3; i++ {
+		Add(status, newEvent("default", "BackOff", "api", start.Add(time.Duration(i+1)*time.Minute)), "")
+	}
+	Add(status, newEvent("default", "Pulled", "web", start.Add(time.Hour)), "")
+	Add(status, newEvent("kube-system", "Pulled", "dns", start.Add(2*time.Hour)), "")
+
+	message := Render(status, "[dev]", 2, start.Add(24*time.Hour))
+
+	for _, want := range []string{
+		"*[dev]*",
+		"from 2025-03-03T00:00:00Z to 2025-03-04T00:00:00Z: *5* events",
+		"*First seen:* 2025-03-03T00:01:00Z",
+		"*Last seen:* 2025-03-03T02:00:00Z",
+		"*By namespace:* default (4), kube-system (1)",
+		"*By reason:* BackOff (3), Pulled (2)",
+		"• Pod default/api (BackOff): 3",
+		"• Pod kube-system/dns (Pulled): 1",
+	} {
+		if !strings.Contains(message, want) {
+			t.Errorf("digest message missing %q:\n%s", want, message)
+		}
+	}
+	if strings.Contains(message, "default/web") {
+		t.Errorf("digest should only list the top 2 offenders:\n%s", message)
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	after := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+
+	next, err := NextRun(&monitoringv1.NotifierDigest{Schedule: "0 9 * * *", TimeZone: "Europe/Berlin"}, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 3, 4, 8, 0, 0, 0, time.UTC); !next.Equal(want) {
+		t.Errorf("NextRun() = %v, want %v", next, want)
+	}
+
+	if _, err := NextRun(&monitoringv1.NotifierDigest{Schedule: "every morning"}, after); err == nil {
+		t.Error("expected an error for an invalid schedule")
+	}
+}
@@ -0,0 +1,187 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	monitoringv1 "github.com/example/notifier/api/v1"
+)
+
+var weekdays = map[string]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+type window struct {
+	days  [7]bool
+	start time.Duration
+	end   time.Duration
+}
+
+// Schedule evaluates a NotifierSchedule against a clock.
+type Schedule struct {
+	OutsideWindow   monitoringv1.OutsideWindowAction
+	FallbackWebhook string
+
+	clock    clock.PassiveClock
+	location *time.Location
+	windows  []window
+}
+
+// New parses spec into a Schedule. It returns nil when spec is nil,
+// meaning notifications are never restricted.
+func New(spec *monitoringv1.NotifierSchedule, clk clock.PassiveClock) (*Schedule, error) {
+	if spec == nil {
+		return nil, nil
+	}
+
+	location := time.UTC
+	if spec.TimeZone != "" {
+		loc, err := time.LoadLocation(spec.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule time zone %q: %w", spec.TimeZone, err)
+		}
+		location = loc
+	}
+
+	outsideWindow := spec.OutsideWindow
+	if outsideWindow == "" {
+		outsideWindow = monitoringv1.OutsideWindowDrop
+	}
+	if outsideWindow == monitoringv1.OutsideWindowFallback && spec.FallbackWebhook == "" {
+		return nil, fmt.Errorf("schedule outsideWindow is %s but no fallbackWebhook is set", outsideWindow)
+	}
+
+	s := &Schedule{
+		OutsideWindow:   outsideWindow,
+		FallbackWebhook: spec.FallbackWebhook,
+		clock:           clk,
+		location:        location,
+	}
+
+	for _, w := range spec.Windows {
+		parsed, err := parseWindow(w)
+		if err != nil {
+			return nil, err
+		}
+		s.windows = append(s.windows, parsed)
+	}
+
+	return s, nil
+}
+
+// Open reports whether the current time falls inside any window.
+func (s *Schedule) Open() bool {
+	return s.openAt(s.clock.Now())
+}
+
+// NextOpen returns the time the next window opens, or the current time
+// if a window is already open. It returns the zero time if no window
+// ever opens.
+func (s *Schedule) NextOpen() time.Time {
+	now := s.clock.Now()
+	if s.openAt(now) {
+		return now
+	}
+
+	local := now.In(s.location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.location)
+
+	var next time.Time
+	for day := 0; day <= 7; day++ {
+		date := midnight.AddDate(0, 0, day)
+		for _, w := range s.windows {
+			if !w.days[date.Weekday()] {
+				continue
+			}
+			start := atOffset(date, w.start)
+			if start.After(now) && (next.IsZero() || start.Before(next)) {
+				next = start
+			}
+		}
+		if !next.IsZero() {
+			return next
+		}
+	}
+
+	return next
+}
+
+func (s *Schedule) openAt(t time.Time) bool {
+	local := t.In(s.location)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	today := local.Weekday()
+	yesterday := (today + 6) % 7
+
+	for _, w := range s.windows {
+		if w.start < w.end {
+			if w.days[today] && offset >= w.start && offset < w.end {
+				return true
+			}
+			continue
+		}
+
+		// the window wraps past midnight
+		if w.days[today] && offset >= w.start {
+			return true
+		}
+		if w.days[yesterday] && offset < w.end {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseWindow(w monitoringv1.TimeWindow) (window, error) {
+	var (
+		parsed window
+		err    error
+	)
+
+	if parsed.start, err = parseClock(w.Start); err != nil {
+		return parsed, err
+	}
+	if parsed.end, err = parseClock(w.End); err != nil {
+		return parsed, err
+	}
+
+	if len(w.Days) == 0 {
+		for i := range parsed.days {
+			parsed.days[i] = true
+		}
+		return parsed, nil
+	}
+
+	for _, d := range w.Days {
+		weekday, ok := weekdays[d]
+		if !ok {
+			return parsed, fmt.Errorf("invalid schedule day %q", d)
+		}
+		parsed.days[weekday] = true
+	}
+
+	return parsed, nil
+}
+
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid schedule time %q: %w", value, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// atOffset returns the wall clock time offset from midnight on date,
+// staying correct across daylight saving transitions.
+func atOffset(date time.Time, offset time.Duration) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(),
+		int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, date.Location())
+}
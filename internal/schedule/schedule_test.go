@@ -0,0 +1,103 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+
+	monitoringv1 "github.com/example/notifier/api/v1"
+)
+
+func TestOpen(t *testing.T) {
+	spec := &monitoringv1.NotifierSchedule{
+		TimeZone: "Europe/Berlin",
+		Windows: []monitoringv1.TimeWindow{
+			{Days: []string{"Mon", "Tue", "Wed", "Thu", "Fri"}, Start: "09:00", End: "18:00"},
+			{Days: []string{"Sat"}, Start: "22:00", End: "02:00"},
+		},
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{"weekday inside", time.Date(2025, 3, 3, 9, 0, 0, 0, berlin), true},
+		{"weekday before start", time.Date(2025, 3, 3, 8, 59, 0, 0, berlin), false},
+		{"weekday at end", time.Date(2025, 3, 3, 18, 0, 0, 0, berlin), false},
+		{"weekday inside in UTC", time.Date(2025, 3, 3, 16, 30, 0, 0, time.UTC), true},
+		{"sunday daytime", time.Date(2025, 3, 2, 12, 0, 0, 0, berlin), false},
+		{"saturday late", time.Date(2025, 3, 1, 23, 0, 0, 0, berlin), true},
+		{"wrapped into sunday", time.Date(2025, 3, 2, 1, 30, 0, 0, berlin), true},
+		{"wrapped window closed", time.Date(2025, 3, 2, 2, 0, 0, 0, berlin), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(spec, clocktesting.NewFakePassiveClock(tt.now))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Open(); got != tt.want {
+				t.Errorf("Open() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextOpen(t *testing.T) {
+	spec := &monitoringv1.NotifierSchedule{
+		Windows: []monitoringv1.TimeWindow{
+			{Days: []string{"Mon", "Wed"}, Start: "09:00", End: "17:00"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"already open", time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC), time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)},
+		{"later the same day", time.Date(2025, 3, 3, 7, 0, 0, 0, time.UTC), time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)},
+		{"skips closed days", time.Date(2025, 3, 3, 18, 0, 0, 0, time.UTC), time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC)},
+		{"wraps the week", time.Date(2025, 3, 5, 18, 0, 0, 0, time.UTC), time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(spec, clocktesting.NewFakePassiveClock(tt.now))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.NextOpen(); !got.Equal(tt.want) {
+				t.Errorf("NextOpen() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	if s, err := New(nil, clocktesting.NewFakePassiveClock(time.Now())); s != nil || err != nil {
+		t.Errorf("New(nil) = %v, %v, want nil, nil", s, err)
+	}
+
+	invalid := []*monitoringv1.NotifierSchedule{
+		{TimeZone: "Mars/Olympus", Windows: []monitoringv1.TimeWindow{{Start: "09:00", End: "17:00"}}},
+		{Windows: []monitoringv1.TimeWindow{{Start: "9am", End: "17:00"}}},
+		{Windows: []monitoringv1.TimeWindow{{Days: []string{"Funday"}, Start: "09:00", End: "17:00"}}},
+		{
+			Windows:       []monitoringv1.TimeWindow{{Start: "09:00", End: "17:00"}},
+			OutsideWindow: monitoringv1.OutsideWindowFallback,
+		},
+	}
+	for _, spec := range invalid {
+		if _, err := New(spec, clocktesting.NewFakePassiveClock(time.Now())); err == nil {
+			t.Errorf("New(%+v) expected an error", spec)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package alertmanager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+const (
+	DefaultQuietPeriod = 15 * time.Minute
+
+	alertName       = "KubernetesEvent"
+	digestAlertName = "KubernetesEventDigest"
+)
+
+// Alert is a postable alert of the Alertmanager v2 API,
+// see https://github.com/prometheus/alertmanager/blob/main/api/v2/openapi.yaml
+type Alert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+type Config struct {
+	// URL of Alertmanager, e.g. http://alertmanager.monitoring:9093
+	URL string
+	// QuietPeriod after the event last occurred at which its alert resolves, unless the event repeats
+	QuietPeriod time.Duration
+	// Labels added to every alert, event labels take precedence
+	Labels map[string]string
+}
+
+// AlertmanagerPublisher fires an alert per event object and reason. Alertmanager
+// resolves the alert once the event has not occurred for the quiet period, as
+// every repetition moves the last timestamp of the event and so endsAt further out.
+type AlertmanagerPublisher struct {
+	Config
+	now func() time.Time
+}
+
+func NewAlertmanagerPublisher(config Config) *AlertmanagerPublisher {
+	if config.QuietPeriod <= 0 {
+		config.QuietPeriod = DefaultQuietPeriod
+	}
+	return &AlertmanagerPublisher{Config: config, now: time.Now}
+}
+
+func (a *AlertmanagerPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	alerts := []Alert{a.buildAlert(notification)}
+	if _, err := publisher.PostJSON(ctx, strings.TrimSuffix(a.URL, "/")+"/api/v2/alerts", alerts, nil); err != nil {
+		return fmt.Errorf("failed to post alertmanager alert: %w", err)
+	}
+	return nil
+}
+
+func (a *AlertmanagerPublisher) buildAlert(notification publisher.Notification) Alert {
+	now := a.now().UTC()
+	labels := make(map[string]string, len(a.Labels)+8)
+	for name, value := range a.Labels {
+		labels[name] = value
+	}
+	if notification.ClusterName != "" {
+		labels["cluster"] = notification.ClusterName
+	}
+
+	event := notification.Event
+	if event == nil {
+		labels["alertname"] = digestAlertName
+		labels["severity"] = "info"
+		return Alert{
+			Labels:      labels,
+			Annotations: map[string]string{"message": notification.Message},
+			StartsAt:    now,
+			EndsAt:      now.Add(a.QuietPeriod),
+		}
+	}
+
+	labels["alertname"] = alertName
+	labels["severity"] = severity(event.Type)
+	labels["namespace"] = event.Namespace
+	labels["reason"] = event.Reason
+	labels["kind"] = event.InvolvedObject.Kind
+	labels["name"] = event.InvolvedObject.Name
+	owner := notification.Owner
+	if owner == "" {
+		owner = event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name
+	}
+	labels["owner"] = owner
+
+	return Alert{
+		Labels:      labels,
+		Annotations: map[string]string{"message": event.Message},
+		StartsAt:    startsAt(event, now),
+		EndsAt:      publisher.EventTime(event, now).UTC().Add(a.QuietPeriod),
+	}
+}
+
+// startsAt is when the event was first seen, so repeated events keep the start of the alert
+func startsAt(event *corev1.Event, now time.Time) time.Time {
+	switch {
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.UTC()
+	case !event.EventTime.IsZero():
+		return event.EventTime.UTC()
+	default:
+		return now
+	}
+}
+
+func severity(eventType string) string {
+	if eventType == corev1.EventTypeWarning {
+		return "warning"
+	}
+	return "info"
+}
@@ -0,0 +1,115 @@
+package alertmanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func TestAlertmanagerPublisherSend(t *testing.T) {
+	var alerts []Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/alerts" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&alerts); err != nil {
+			t.Errorf("failed to decode alerts: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	firstSeen := now.Add(-10 * time.Minute)
+	lastSeen := now.Add(-2 * time.Minute)
+	p := NewAlertmanagerPublisher(Config{
+		URL:         server.URL + "/",
+		QuietPeriod: 5 * time.Minute,
+		Labels:      map[string]string{"team": "payments", "namespace": "overridden"},
+	})
+	p.now = func() time.Time { return now }
+
+	event := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "payments"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "payments", Name: "api-7d9f-x2"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Type:           corev1.EventTypeWarning,
+		FirstTimestamp: metav1.NewTime(firstSeen),
+		LastTimestamp:  metav1.NewTime(lastSeen),
+	}
+	err := p.Send(context.Background(), publisher.Notification{Event: event, ClusterName: "prod", Owner: "Deployment/api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(alerts) != 1 {
+		t.Fatalf("expected one alert, got %+v", alerts)
+	}
+	alert := alerts[0]
+	want := map[string]string{
+		"alertname": "KubernetesEvent",
+		"severity":  "warning",
+		"cluster":   "prod",
+		"team":      "payments",
+		"namespace": "payments",
+		"reason":    "BackOff",
+		"kind":      "Pod",
+		"name":      "api-7d9f-x2",
+		"owner":     "Deployment/api",
+	}
+	for name, value := range want {
+		if alert.Labels[name] != value {
+			t.Errorf("label %s = %q, want %q", name, alert.Labels[name], value)
+		}
+	}
+	if len(alert.Labels) != len(want) {
+		t.Errorf("unexpected labels %v", alert.Labels)
+	}
+	if alert.Annotations["message"] != event.Message {
+		t.Errorf("unexpected annotations %v", alert.Annotations)
+	}
+	// an event re-sent after deduplication expires must not keep its alert firing
+	if !alert.StartsAt.Equal(firstSeen) || !alert.EndsAt.Equal(lastSeen.Add(5*time.Minute)) {
+		t.Errorf("unexpected alert window %s - %s", alert.StartsAt, alert.EndsAt)
+	}
+}
+
+func TestAlertmanagerPublisherDefaults(t *testing.T) {
+	p := NewAlertmanagerPublisher(Config{})
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	event := &corev1.Event{InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "worker-1"}, Type: corev1.EventTypeNormal}
+	alert := p.buildAlert(publisher.Notification{Event: event})
+	if alert.Labels["owner"] != "Node/worker-1" || alert.Labels["severity"] != "info" {
+		t.Errorf("unexpected labels %v", alert.Labels)
+	}
+	if !alert.StartsAt.Equal(now) || !alert.EndsAt.Equal(now.Add(DefaultQuietPeriod)) {
+		t.Errorf("unexpected alert window %s - %s", alert.StartsAt, alert.EndsAt)
+	}
+
+	digest := p.buildAlert(publisher.Notification{Message: "3 events"})
+	if digest.Labels["alertname"] != "KubernetesEventDigest" || digest.Annotations["message"] != "3 events" {
+		t.Errorf("unexpected digest alert %+v", digest)
+	}
+}
+
+func TestAlertmanagerPublisherError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`"start time must be before end time"`))
+	}))
+	defer server.Close()
+
+	if err := NewAlertmanagerPublisher(Config{URL: server.URL}).Send(context.Background(), publisher.Notification{Message: "digest"}); err == nil {
+		t.Error("expected an error for a rejected alert")
+	}
+}
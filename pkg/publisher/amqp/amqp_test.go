@@ -0,0 +1,162 @@
+package amqp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+type published struct {
+	exchange string
+	key      string
+	message  amqp.Publishing
+}
+
+// fakeSession records what is published, failing with err if set
+type fakeSession struct {
+	messages []published
+	err      error
+	isClosed bool
+}
+
+func (f *fakeSession) publish(_ context.Context, exchange, key string, message amqp.Publishing) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.messages = append(f.messages, published{exchange, key, message})
+	return nil
+}
+
+func (f *fakeSession) closed() bool { return f.isClosed }
+
+func (f *fakeSession) Close() error {
+	f.isClosed = true
+	return nil
+}
+
+func backOffEvent() *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop", UID: "e1"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "cart-7f9c-x2"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Type:           corev1.EventTypeWarning,
+		Count:          3,
+	}
+}
+
+func TestAMQPPublisherSend(t *testing.T) {
+	fake := &fakeSession{}
+	p, err := NewAMQPPublisher(newConn(func() (session, error) { return fake, nil }), "k8s", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notification := publisher.Notification{Event: backOffEvent(), ClusterName: "prod", Owner: "Deployment/cart"}
+	if err := p.Send(context.Background(), notification); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(context.Background(), publisher.Notification{Message: "3 events"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fake.messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(fake.messages))
+	}
+	event := fake.messages[0]
+	if event.exchange != "k8s" || event.key != "k8s.events.shop.BackOff" {
+		t.Errorf("unexpected routing %s %s", event.exchange, event.key)
+	}
+	if event.message.DeliveryMode != amqp.Persistent || event.message.MessageId != "e1-3" || event.message.Headers["reason"] != "BackOff" {
+		t.Errorf("unexpected message properties %+v", event.message)
+	}
+	var record publisher.Record
+	if err := json.Unmarshal(event.message.Body, &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Owner != "Deployment/cart" || record.Event == nil || record.Event.Reason != "BackOff" {
+		t.Errorf("unexpected record %s", event.message.Body)
+	}
+
+	digest := fake.messages[1]
+	if digest.key != DefaultDigestRoutingKey || digest.message.MessageId != "" || digest.message.DeliveryMode != amqp.Persistent {
+		t.Errorf("unexpected digest %+v", digest)
+	}
+}
+
+func TestConnReconnects(t *testing.T) {
+	var sessions []*fakeSession
+	conn := newConn(func() (session, error) {
+		s := &fakeSession{}
+		sessions = append(sessions, s)
+		return s, nil
+	})
+	p, err := NewAMQPPublisher(conn, "", "{{ .ClusterName }}.{{ .Owner }}", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 0 {
+		t.Fatal("expected the connection to be dialed on the first publish")
+	}
+	notification := publisher.Notification{Event: backOffEvent(), ClusterName: "prod", Owner: "Deployment/cart"}
+	if err := p.Send(context.Background(), notification); err != nil {
+		t.Fatal(err)
+	}
+
+	// closed by the broker between publishes
+	sessions[0].isClosed = true
+	if err := p.Send(context.Background(), notification); err != nil {
+		t.Fatal(err)
+	}
+	// closed while publishing
+	sessions[1].err = amqp.ErrClosed
+	if err := p.Send(context.Background(), notification); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sessions) != 3 || len(sessions[0].messages) != 1 || len(sessions[1].messages) != 1 || len(sessions[2].messages) != 1 {
+		t.Fatalf("expected a new session after each drop, got %d", len(sessions))
+	}
+	if key := sessions[2].messages[0].key; key != "prod.Deployment/cart" {
+		t.Errorf("unexpected routing key %q", key)
+	}
+
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(context.Background(), notification); err == nil {
+		t.Error("expected an error publishing on a closed connection")
+	}
+}
+
+func TestAMQPPublisherErrors(t *testing.T) {
+	dials := 0
+	unreachable, _ := NewAMQPPublisher(newConn(func() (session, error) {
+		dials++
+		return nil, errors.New("connection refused")
+	}), "k8s", "", "")
+	for range 2 {
+		if err := unreachable.Send(context.Background(), publisher.Notification{Event: backOffEvent()}); err == nil {
+			t.Error("expected an error for an unreachable broker")
+		}
+	}
+	if dials != 2 {
+		t.Errorf("expected every publish to dial again, got %d dials", dials)
+	}
+	if _, err := NewAMQPPublisher(nil, "", "{{ .Namespace", ""); err == nil {
+		t.Error("expected an error for an invalid routing key template")
+	}
+
+	nack := errors.New("message was not confirmed by the broker")
+	p, _ := NewAMQPPublisher(newConn(func() (session, error) { return &fakeSession{err: nack}, nil }), "k8s", "", "")
+	if err := p.Send(context.Background(), publisher.Notification{Event: backOffEvent()}); !errors.Is(err, nack) {
+		t.Errorf("expected the confirmation error, got %v", err)
+	}
+}
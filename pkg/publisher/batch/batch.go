@@ -0,0 +1,174 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSize     = 100
+	DefaultInterval = 5 * time.Second
+	// DefaultMaxAttempts bounds how often items are sent before they are dropped
+	DefaultMaxAttempts = 3
+	DefaultBackoff     = time.Second
+
+	// queueBatches is how many batches can wait before Add blocks
+	queueBatches = 10
+	flushTimeout = 30 * time.Second
+)
+
+// Options tune a Batcher, zero values select the defaults
+type Options struct {
+	// Size is the number of items flushed together
+	Size int
+	// Interval is the longest an item waits for its batch to fill up
+	Interval time.Duration
+	// QueueSize is the number of items that can wait before Add blocks,
+	// ten batches by default
+	QueueSize int
+	// MaxAttempts bounds how often items are sent before they are dropped
+	MaxAttempts int
+	// Backoff is the wait before the first retry, doubled for each further one
+	Backoff time.Duration
+}
+
+// FlushFunc sends a batch. It returns the items that failed transiently and
+// should be sent again, and an error describing every failure.
+type FlushFunc[T any] func(ctx context.Context, items []T) (retry []T, err error)
+
+// Batcher collects items in the background and flushes them once the batch is
+// full or the flush interval elapsed. Failures are reported to the error handler.
+type Batcher[T any] struct {
+	mu       sync.RWMutex
+	closed   bool
+	items    chan T
+	done     chan struct{}
+	size     int
+	interval time.Duration
+	flush    FlushFunc[T]
+	onError  func(error)
+	attempts int
+	// backoff is the wait before the first retry, doubled for each further one
+	backoff time.Duration
+}
+
+func New[T any](size int, interval time.Duration, flush FlushFunc[T], onError func(error)) *Batcher[T] {
+	return NewWithOptions(Options{Size: size, Interval: interval}, flush, onError)
+}
+
+func NewWithOptions[T any](options Options, flush FlushFunc[T], onError func(error)) *Batcher[T] {
+	if options.Size <= 0 {
+		options.Size = DefaultSize
+	}
+	if options.Interval <= 0 {
+		options.Interval = DefaultInterval
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = options.Size * queueBatches
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = DefaultMaxAttempts
+	}
+	if options.Backoff <= 0 {
+		options.Backoff = DefaultBackoff
+	}
+	b := &Batcher[T]{
+		items:    make(chan T, options.QueueSize),
+		done:     make(chan struct{}),
+		size:     options.Size,
+		interval: options.Interval,
+		flush:    flush,
+		onError:  onError,
+		attempts: options.MaxAttempts,
+		backoff:  options.Backoff,
+	}
+	go b.run()
+	return b
+}
+
+// Add queues an item, waiting for room while the queue is full
+func (b *Batcher[T]) Add(ctx context.Context, item T) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return errors.New("batcher is closed")
+	}
+	select {
+	case b.items <- item:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close flushes the queued items and stops the batcher
+func (b *Batcher[T]) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.items)
+	b.mu.Unlock()
+
+	<-b.done
+	return nil
+}
+
+func (b *Batcher[T]) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	var pending []T
+	for {
+		select {
+		case item, ok := <-b.items:
+			if !ok {
+				b.send(pending)
+				return
+			}
+			pending = append(pending, item)
+			if len(pending) >= b.size {
+				b.send(pending)
+				pending = nil
+			}
+		case <-ticker.C:
+			b.send(pending)
+			pending = nil
+		}
+	}
+}
+
+// send flushes items, retrying transient failures with exponential backoff
+func (b *Batcher[T]) send(items []T) {
+	for attempt := 1; len(items) > 0; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+		retry, err := b.flush(ctx, items)
+		cancel()
+		if err != nil {
+			b.report(err)
+		}
+		if len(retry) == 0 {
+			return
+		}
+		if attempt >= b.attempts {
+			b.report(fmt.Errorf("dropped %d items after %d attempts", len(retry), attempt))
+			return
+		}
+		time.Sleep(b.backoff << (attempt - 1))
+		items = retry
+	}
+}
+
+func (b *Batcher[T]) report(err error) {
+	if b.onError != nil {
+		b.onError(err)
+	}
+}
@@ -0,0 +1,107 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder records the batches it is asked to flush
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]int
+	flushed chan struct{}
+}
+
+func newRecorder() *recorder {
+	return &recorder{flushed: make(chan struct{}, 100)}
+}
+
+func (r *recorder) flush(_ context.Context, items []int) ([]int, error) {
+	r.mu.Lock()
+	r.batches = append(r.batches, append([]int(nil), items...))
+	r.mu.Unlock()
+	r.flushed <- struct{}{}
+	return nil, nil
+}
+
+func (r *recorder) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-r.flushed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a flush")
+	}
+}
+
+func TestBatcherFlushesFullBatches(t *testing.T) {
+	r := newRecorder()
+	b := New(2, time.Hour, r.flush, nil)
+
+	for i := range 5 {
+		if err := b.Add(context.Background(), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.wait(t)
+	r.wait(t)
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the last item is flushed on close
+	if len(r.batches) != 3 || len(r.batches[0]) != 2 || r.batches[2][0] != 4 {
+		t.Errorf("unexpected batches %v", r.batches)
+	}
+	if err := b.Add(context.Background(), 6); err == nil {
+		t.Error("expected an error adding to a closed batcher")
+	}
+}
+
+func TestBatcherFlushesOnInterval(t *testing.T) {
+	r := newRecorder()
+	b := New(100, 10*time.Millisecond, r.flush, nil)
+	defer func() { _ = b.Close() }()
+
+	if err := b.Add(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	r.wait(t)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.batches) != 1 || len(r.batches[0]) != 1 {
+		t.Errorf("unexpected batches %v", r.batches)
+	}
+}
+
+func TestBatcherRetriesTransientFailures(t *testing.T) {
+	var attempts [][]int
+	var errs []error
+	flush := func(_ context.Context, items []int) ([]int, error) {
+		attempts = append(attempts, items)
+		// the first item is rejected, the others are to be sent again
+		return items[1:], errors.New("partial failure")
+	}
+	b := New(4, time.Hour, flush, func(err error) { errs = append(errs, err) })
+	b.backoff = time.Millisecond
+
+	for i := range 4 {
+		if err := b.Add(context.Background(), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(attempts) != DefaultMaxAttempts || len(attempts[1]) != 3 || len(attempts[2]) != 2 {
+		t.Errorf("unexpected attempts %v", attempts)
+	}
+	if len(errs) != DefaultMaxAttempts+1 || !strings.Contains(errs[DefaultMaxAttempts].Error(), "dropped 1 items") {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
@@ -0,0 +1,166 @@
+package cloudevents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+// Content modes of the HTTP protocol binding,
+// see https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/bindings/http-protocol-binding.md
+const (
+	Structured = "Structured"
+	Binary     = "Binary"
+)
+
+const (
+	SpecVersion = "1.0"
+	// TypePrefix is prepended to the event reason to form the CloudEvent type
+	TypePrefix = "io.k8s.event."
+	DigestType = TypePrefix + "digest"
+
+	structuredContentType = "application/cloudevents+json; charset=utf-8"
+	dataContentType       = "application/json"
+)
+
+// Event is a CloudEvent in the JSON event format
+type Event struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            string          `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// digestData is the payload of digest CloudEvents
+type digestData struct {
+	Message string `json:"message"`
+}
+
+// CloudEventsPublisher emits notifications as CloudEvents 1.0 to an HTTP sink
+type CloudEventsPublisher struct {
+	SinkURL string
+	// Mode is the content mode, Structured or Binary
+	Mode string
+}
+
+func NewCloudEventsPublisher(sinkURL, mode string) *CloudEventsPublisher {
+	if mode == "" {
+		mode = Structured
+	}
+	return &CloudEventsPublisher{SinkURL: sinkURL, Mode: mode}
+}
+
+func (c *CloudEventsPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	event, err := buildEvent(notification, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var (
+		body   []byte
+		header = http.Header{}
+	)
+	if c.Mode == Binary {
+		body = event.Data
+		header.Set("Content-Type", event.DataContentType)
+		header.Set("ce-specversion", event.SpecVersion)
+		header.Set("ce-id", event.ID)
+		header.Set("ce-source", event.Source)
+		header.Set("ce-type", event.Type)
+		if event.Subject != "" {
+			header.Set("ce-subject", event.Subject)
+		}
+		if event.Time != "" {
+			header.Set("ce-time", event.Time)
+		}
+	} else {
+		if body, err = json.Marshal(event); err != nil {
+			return err
+		}
+		header.Set("Content-Type", structuredContentType)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.SinkURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+
+	if _, err := publisher.Do(req); err != nil {
+		return fmt.Errorf("failed to send cloudevent: %w", err)
+	}
+	return nil
+}
+
+func buildEvent(notification publisher.Notification, now time.Time) (Event, error) {
+	event := notification.Event
+	if event == nil {
+		data, err := json.Marshal(digestData{Message: notification.Message})
+		if err != nil {
+			return Event{}, err
+		}
+		return Event{
+			SpecVersion:     SpecVersion,
+			ID:              string(uuid.NewUUID()),
+			Source:          Source(notification),
+			Type:            DigestType,
+			Time:            now.UTC().Format(time.RFC3339),
+			DataContentType: dataContentType,
+			Data:            data,
+		}, nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	return Event{
+		SpecVersion:     SpecVersion,
+		ID:              publisher.EventID(event),
+		Source:          Source(notification),
+		Type:            TypePrefix + event.Reason,
+		Subject:         event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+		Time:            publisher.EventTime(event, now).UTC().Format(time.RFC3339),
+		DataContentType: dataContentType,
+		Data:            data,
+	}, nil
+}
+
+// Source identifies the object the event is about as
+// /clusters/{cluster}/namespaces/{namespace}/{kind}/{name}, without the parts that are unknown
+func Source(notification publisher.Notification) string {
+	var segments []string
+	if notification.ClusterName != "" {
+		segments = append(segments, "clusters", notification.ClusterName)
+	}
+
+	if event := notification.Event; event != nil {
+		namespace := event.InvolvedObject.Namespace
+		if namespace == "" {
+			namespace = event.Namespace
+		}
+		if namespace != "" {
+			segments = append(segments, "namespaces", namespace)
+		}
+		segments = append(segments, strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name)
+	}
+
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,146 @@
+package cloudevents
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+func fakeSink(t *testing.T) (*httptest.Server, *[]request) {
+	t.Helper()
+
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read body: %v", err)
+		}
+		requests = append(requests, request{header: r.Header, body: body})
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func backOffEvent() *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop", Name: "cart-0.17a", UID: "5c1e6f7a-0d7e-4d7b-9c57-1f3b2f6d9a10"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "cart-0"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Type:           corev1.EventTypeWarning,
+		Count:          3,
+		LastTimestamp:  metav1.NewTime(time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)),
+	}
+}
+
+func TestCloudEventsPublisherStructured(t *testing.T) {
+	server, requests := fakeSink(t)
+
+	err := NewCloudEventsPublisher(server.URL, "").Send(context.Background(), publisher.Notification{Event: backOffEvent(), ClusterName: "eu west"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := (*requests)[0]
+	if got := r.header.Get("Content-Type"); got != structuredContentType {
+		t.Errorf("unexpected content type %q", got)
+	}
+	var event map[string]any
+	if err := json.Unmarshal(r.body, &event); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"specversion":     "1.0",
+		"id":              "5c1e6f7a-0d7e-4d7b-9c57-1f3b2f6d9a10-3",
+		"source":          "/clusters/eu%20west/namespaces/shop/pod/cart-0",
+		"type":            "io.k8s.event.BackOff",
+		"subject":         "Pod/cart-0",
+		"time":            "2024-05-01T08:30:00Z",
+		"datacontenttype": "application/json",
+	}
+	for attribute, value := range want {
+		if event[attribute] != value {
+			t.Errorf("%s = %v, want %s", attribute, event[attribute], value)
+		}
+	}
+
+	data, _ := event["data"].(map[string]any)
+	if data["reason"] != "BackOff" || data["message"] != "Back-off restarting failed container" {
+		t.Errorf("expected the original event as data, got %v", event["data"])
+	}
+}
+
+func TestCloudEventsPublisherBinary(t *testing.T) {
+	server, requests := fakeSink(t)
+
+	event := backOffEvent()
+	event.Count = 1
+	if err := NewCloudEventsPublisher(server.URL, Binary).Send(context.Background(), publisher.Notification{Event: event}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := (*requests)[0]
+	want := map[string]string{
+		"Content-Type":   "application/json",
+		"Ce-Specversion": "1.0",
+		"Ce-Id":          "5c1e6f7a-0d7e-4d7b-9c57-1f3b2f6d9a10",
+		"Ce-Source":      "/namespaces/shop/pod/cart-0",
+		"Ce-Type":        "io.k8s.event.BackOff",
+		"Ce-Subject":     "Pod/cart-0",
+	}
+	for name, value := range want {
+		if got := r.header.Get(name); got != value {
+			t.Errorf("header %s = %q, want %q", name, got, value)
+		}
+	}
+
+	var data corev1.Event
+	if err := json.Unmarshal(r.body, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.UID != event.UID || data.InvolvedObject.Name != "cart-0" {
+		t.Errorf("expected the original event as body, got %+v", data)
+	}
+}
+
+func TestCloudEventsPublisherDigest(t *testing.T) {
+	server, requests := fakeSink(t)
+	p := NewCloudEventsPublisher(server.URL, Structured)
+
+	for range 2 {
+		if err := p.Send(context.Background(), publisher.Notification{Message: "3 events", ClusterName: "prod"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var first, second Event
+	if err := json.Unmarshal((*requests)[0].body, &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal((*requests)[1].body, &second); err != nil {
+		t.Fatal(err)
+	}
+	if first.Type != DigestType || first.Source != "/clusters/prod" || string(first.Data) != `{"message":"3 events"}` {
+		t.Errorf("unexpected digest event %+v", first)
+	}
+	if first.ID == "" || first.ID == second.ID {
+		t.Errorf("expected unique digest IDs, got %q and %q", first.ID, second.ID)
+	}
+}
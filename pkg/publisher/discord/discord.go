@@ -0,0 +1,166 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+// Embed limits, see https://discord.com/developers/docs/resources/message#embed-object-embed-limits
+const (
+	maxTitle       = 256
+	maxDescription = 4096
+	maxFieldName   = 256
+	maxFieldValue  = 1024
+	maxFooter      = 2048
+)
+
+const (
+	colorWarning = 0xE67E22
+	colorNormal  = 0x2ECC71
+	colorOther   = 0x95A5A6
+)
+
+const (
+	// maxRetries is how often a rate limited message is retried
+	maxRetries = 3
+	// maxRetryAfter caps how long a rate limited message waits before retrying
+	maxRetryAfter = 30 * time.Second
+)
+
+type Payload struct {
+	Embeds []Embed `json:"embeds"`
+}
+
+type Embed struct {
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Color       int     `json:"color"`
+	Fields      []Field `json:"fields,omitempty"`
+	Timestamp   string  `json:"timestamp,omitempty"`
+	Footer      *Footer `json:"footer,omitempty"`
+}
+
+type Field struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type Footer struct {
+	Text string `json:"text"`
+}
+
+// rateLimit is the body Discord answers with on 429 Too Many Requests
+type rateLimit struct {
+	Message    string  `json:"message"`
+	RetryAfter float64 `json:"retry_after"`
+	Global     bool    `json:"global"`
+}
+
+type DiscordPublisher struct {
+	WebhookURL string
+}
+
+func NewDiscordPublisher(webhookURL string) *DiscordPublisher {
+	return &DiscordPublisher{WebhookURL: webhookURL}
+}
+
+func (d *DiscordPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	payload := Payload{Embeds: []Embed{buildEmbed(notification)}}
+
+	for attempt := 0; ; attempt++ {
+		_, err := publisher.PostJSON(ctx, d.WebhookURL, payload, nil)
+
+		var httpErr *publisher.HTTPError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusTooManyRequests || attempt == maxRetries {
+			if err != nil {
+				return fmt.Errorf("failed to send discord webhook: %w", err)
+			}
+			return nil
+		}
+
+		timer := time.NewTimer(retryAfter(httpErr))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func buildEmbed(notification publisher.Notification) Embed {
+	event := notification.Event
+	if event == nil {
+		embed := Embed{Description: publisher.Truncate(notification.Message, maxDescription), Color: colorOther}
+		if notification.ClusterName != "" {
+			embed.Footer = &Footer{Text: publisher.Truncate("Cluster "+notification.ClusterName, maxFooter)}
+		}
+		return embed
+	}
+
+	embed := Embed{
+		Title:       publisher.Truncate(fmt.Sprintf("%s: %s %s", event.Reason, event.InvolvedObject.Kind, event.InvolvedObject.Name), maxTitle),
+		Description: publisher.Truncate(event.Message, maxDescription),
+		Color:       color(event.Type),
+		Fields: []Field{
+			field("Namespace", event.Namespace),
+			field("Kind", event.InvolvedObject.Kind),
+			field("Name", event.InvolvedObject.Name),
+			field("Reason", event.Reason),
+			field("Type", event.Type),
+		},
+	}
+	if event.Count > 1 {
+		embed.Fields = append(embed.Fields, field("Count", strconv.Itoa(int(event.Count))))
+	}
+	if !event.LastTimestamp.IsZero() {
+		embed.Timestamp = event.LastTimestamp.UTC().Format(time.RFC3339)
+	}
+	if notification.ClusterName != "" {
+		embed.Footer = &Footer{Text: publisher.Truncate("Cluster "+notification.ClusterName, maxFooter)}
+	}
+
+	return embed
+}
+
+func field(name, value string) Field {
+	if value == "" {
+		value = "-"
+	}
+	return Field{Name: publisher.Truncate(name, maxFieldName), Value: publisher.Truncate(value, maxFieldValue), Inline: true}
+}
+
+func color(eventType string) int {
+	switch eventType {
+	case corev1.EventTypeWarning:
+		return colorWarning
+	case corev1.EventTypeNormal:
+		return colorNormal
+	default:
+		return colorOther
+	}
+}
+
+// retryAfter reads how long Discord asks to wait from the 429 body, falling back to the Retry-After header
+func retryAfter(httpErr *publisher.HTTPError) time.Duration {
+	wait := time.Second
+
+	var limit rateLimit
+	if err := json.Unmarshal(httpErr.Body, &limit); err == nil && limit.RetryAfter > 0 {
+		wait = time.Duration(limit.RetryAfter * float64(time.Second))
+	} else if seconds, err := strconv.ParseFloat(httpErr.Header.Get("Retry-After"), 64); err == nil && seconds > 0 {
+		wait = time.Duration(seconds * float64(time.Second))
+	}
+
+	return min(wait, maxRetryAfter)
+}
@@ -0,0 +1,102 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func warningEvent() *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web-0"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Type:           corev1.EventTypeWarning,
+	}
+}
+
+func TestDiscordPublisherSend(t *testing.T) {
+	var payload Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	err := NewDiscordPublisher(server.URL).Send(context.Background(), publisher.Notification{
+		Message:     "ignored for events",
+		Event:       warningEvent(),
+		ClusterName: "edge",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(payload.Embeds) != 1 {
+		t.Fatalf("expected one embed, got %+v", payload.Embeds)
+	}
+	embed := payload.Embeds[0]
+	if embed.Title != "BackOff: Pod web-0" || embed.Description != "Back-off restarting failed container" {
+		t.Errorf("unexpected embed %+v", embed)
+	}
+	if embed.Color != colorWarning {
+		t.Errorf("expected warning colour, got %x", embed.Color)
+	}
+	if len(embed.Fields) != 5 || embed.Fields[0].Name != "Namespace" || embed.Fields[0].Value != "default" {
+		t.Errorf("unexpected fields %+v", embed.Fields)
+	}
+	if embed.Footer == nil || embed.Footer.Text != "Cluster edge" {
+		t.Errorf("unexpected footer %+v", embed.Footer)
+	}
+}
+
+func TestDiscordPublisherRateLimited(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 0.01, "global": false}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := NewDiscordPublisher(server.URL).Send(context.Background(), publisher.Notification{Event: warningEvent()}); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("expected the rate limited message to be retried once, got %d requests", requests)
+	}
+}
+
+func TestDiscordPublisherGivesUp(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"retry_after": 0.001}`))
+	}))
+	defer server.Close()
+
+	err := NewDiscordPublisher(server.URL).Send(context.Background(), publisher.Notification{Message: "digest"})
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("expected a rate limit error, got %v", err)
+	}
+	if requests != maxRetries+1 {
+		t.Errorf("expected %d requests, got %d", maxRetries+1, requests)
+	}
+}
@@ -0,0 +1,186 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/example/notifier/pkg/publisher"
+	"github.com/example/notifier/pkg/publisher/batch"
+)
+
+const DefaultIndex = "k8s-events"
+
+var now = time.Now
+
+// Config defines the cluster and index events are written to
+type Config struct {
+	// URL of the cluster, such as https://elasticsearch:9200
+	URL string
+	// Index, alias or data stream documents are created in
+	Index string
+	// Pipeline is the ingest pipeline documents go through, if set
+	Pipeline string
+	Username string
+	Password string
+	// APIKey is the base64 encoded id:key, used instead of basic authentication
+	APIKey string
+	TLS    *publisher.TLSConfig
+	// BatchSize is the number of documents sent per bulk request
+	BatchSize int
+	// FlushInterval is the longest a document waits for its batch to fill up
+	FlushInterval time.Duration
+}
+
+// document is what is indexed for a notification
+type document struct {
+	// id makes retried creates idempotent, empty for digests
+	id        string
+	Timestamp time.Time `json:"@timestamp"`
+	publisher.Record
+}
+
+// bulkResponse holds the per document results of a bulk request
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// ElasticsearchPublisher indexes notifications through the bulk API. Documents
+// are created with the event ID, so documents sent again are not duplicated.
+type ElasticsearchPublisher struct {
+	config  Config
+	client  *http.Client
+	batcher *batch.Batcher[document]
+}
+
+func NewElasticsearchPublisher(config Config, onError func(error)) (*ElasticsearchPublisher, error) {
+	if config.Index == "" {
+		config.Index = DefaultIndex
+	}
+	client, err := config.TLS.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &ElasticsearchPublisher{config: config, client: client}
+	e.batcher = batch.New(config.BatchSize, config.FlushInterval, e.flush, onError)
+	return e, nil
+}
+
+func (e *ElasticsearchPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	doc := document{Timestamp: now(), Record: publisher.NewRecord(notification)}
+	if event := notification.Event; event != nil {
+		doc.id = publisher.EventID(event)
+		doc.Timestamp = publisher.EventTime(event, doc.Timestamp)
+	}
+
+	if err := e.batcher.Add(ctx, doc); err != nil {
+		return fmt.Errorf("failed to queue elasticsearch document: %w", err)
+	}
+	return nil
+}
+
+// Close sends the queued documents
+func (e *ElasticsearchPublisher) Close() error {
+	return e.batcher.Close()
+}
+
+// flush sends a bulk request, returning the documents rejected transiently:
+// all of them when the request fails, those refused with 429 or a 5xx status otherwise
+func (e *ElasticsearchPublisher) flush(ctx context.Context, docs []document) ([]document, error) {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, doc := range docs {
+		action := map[string]string{"_index": e.config.Index}
+		if doc.id != "" {
+			action["_id"] = doc.id
+		}
+		if err := encoder.Encode(map[string]any{"create": action}); err != nil {
+			return nil, err
+		}
+		if err := encoder.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint := strings.TrimSuffix(e.config.URL, "/") + "/_bulk"
+	if e.config.Pipeline != "" {
+		endpoint += "?pipeline=" + url.QueryEscape(e.config.Pipeline)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	switch {
+	case e.config.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+e.config.APIKey)
+	case e.config.Username != "":
+		req.SetBasicAuth(e.config.Username, e.config.Password)
+	}
+
+	responseBody, err := publisher.DoWith(e.client, req)
+	if err != nil {
+		var httpErr *publisher.HTTPError
+		if errors.As(err, &httpErr) && !retryable(httpErr.StatusCode) {
+			return nil, fmt.Errorf("elasticsearch rejected %d documents: %w", len(docs), err)
+		}
+		return docs, fmt.Errorf("failed to send %d documents to elasticsearch: %w", len(docs), err)
+	}
+
+	var response bulkResponse
+	if err := json.Unmarshal(responseBody, &response); err != nil {
+		return nil, fmt.Errorf("invalid bulk response: %w", err)
+	}
+	if !response.Errors {
+		return nil, nil
+	}
+
+	var retry []document
+	var failures []string
+	for i, item := range response.Items {
+		if i >= len(docs) {
+			break
+		}
+		for _, result := range item {
+			switch {
+			case result.Status < 300, result.Status == http.StatusConflict:
+				// created, or created by an earlier attempt
+			case retryable(result.Status):
+				retry = append(retry, docs[i])
+			default:
+				reason := http.StatusText(result.Status)
+				if result.Error != nil {
+					reason = result.Error.Type + ": " + result.Error.Reason
+				}
+				failures = append(failures, reason)
+			}
+		}
+	}
+
+	if len(retry) == 0 && len(failures) == 0 {
+		return nil, nil
+	}
+	err = fmt.Errorf("elasticsearch rejected %d of %d documents, %d to be retried", len(failures), len(docs), len(retry))
+	if len(failures) > 0 {
+		err = fmt.Errorf("%w: %s", err, failures[0])
+	}
+	return retry, err
+}
+
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
@@ -0,0 +1,140 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func backOffEvent(uid string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop", UID: types.UID(uid)},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "cart-0"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Type:           corev1.EventTypeWarning,
+		LastTimestamp:  metav1.NewTime(time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)),
+	}
+}
+
+func TestElasticsearchPublisherSend(t *testing.T) {
+	requests := make(chan []map[string]any, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" || r.URL.Query().Get("pipeline") != "k8s-events" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		if r.Header.Get("Authorization") != "ApiKey a2V5" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		var lines []map[string]any
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var line map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				t.Error(err)
+			}
+			lines = append(lines, line)
+		}
+		requests <- lines
+		_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer server.Close()
+
+	p, err := NewElasticsearchPublisher(Config{URL: server.URL, Pipeline: "k8s-events", APIKey: "a2V5"}, func(err error) {
+		t.Errorf("unexpected error: %v", err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := backOffEvent("e1")
+	event.Count = 2
+	if err := p.Send(context.Background(), publisher.Notification{Event: event, ClusterName: "prod", Owner: "StatefulSet/cart"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(context.Background(), publisher.Notification{Message: "3 events"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := <-requests
+	if len(lines) != 4 {
+		t.Fatalf("expected 2 actions and 2 documents, got %v", lines)
+	}
+	action := lines[0]["create"].(map[string]any)
+	if action["_index"] != DefaultIndex || action["_id"] != "e1-2" {
+		t.Errorf("unexpected action %v", action)
+	}
+	doc := lines[1]
+	if doc["@timestamp"] != "2024-05-01T12:30:00Z" || doc["cluster"] != "prod" || doc["owner"] != "StatefulSet/cart" {
+		t.Errorf("unexpected document %v", doc)
+	}
+	if _, ok := lines[2]["create"].(map[string]any)["_id"]; ok {
+		t.Errorf("expected digests without an ID, got %v", lines[2])
+	}
+	if lines[3]["message"] != "3 events" {
+		t.Errorf("unexpected digest %v", lines[3])
+	}
+}
+
+func TestElasticsearchPublisherPartialFailure(t *testing.T) {
+	status, body := 0, ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		if user != "notifier" || password != "secret" {
+			t.Errorf("unexpected credentials %s:%s", user, password)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	p, err := NewElasticsearchPublisher(Config{URL: server.URL, Username: "notifier", Password: "secret"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = p.Close() }()
+
+	var docs []document
+	for _, uid := range []string{"e1", "e2", "e3", "e4"} {
+		docs = append(docs, document{id: uid, Record: publisher.NewRecord(publisher.Notification{Event: backOffEvent(uid)})})
+	}
+
+	status = http.StatusOK
+	body = `{"errors":true,"items":[
+		{"create":{"status":201}},
+		{"create":{"status":409,"error":{"type":"version_conflict_engine_exception","reason":"document already exists"}}},
+		{"create":{"status":429,"error":{"type":"es_rejected_execution_exception","reason":"rejected"}}},
+		{"create":{"status":400,"error":{"type":"document_parsing_exception","reason":"failed to parse field"}}}
+	]}`
+	retry, err := p.flush(context.Background(), docs)
+	if len(retry) != 1 || retry[0].id != "e3" {
+		t.Errorf("expected the rejected document to be retried, got %v", retry)
+	}
+	if err == nil || !strings.Contains(err.Error(), "document_parsing_exception") {
+		t.Errorf("expected the parsing failure to be reported, got %v", err)
+	}
+
+	status, body = http.StatusServiceUnavailable, `{"error":"unavailable"}`
+	if retry, err := p.flush(context.Background(), docs); err == nil || len(retry) != len(docs) {
+		t.Errorf("expected every document to be retried, got %d: %v", len(retry), err)
+	}
+
+	status, body = http.StatusUnauthorized, `{"error":"unauthorized"}`
+	if retry, err := p.flush(context.Background(), docs); err == nil || len(retry) != 0 {
+		t.Errorf("expected no retries, got %d: %v", len(retry), err)
+	}
+}
@@ -0,0 +1,358 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+// TLS modes for the SMTP connection
+const (
+	StartTLS = "StartTLS"
+	TLS      = "TLS"
+	NoTLS    = "None"
+)
+
+const (
+	DefaultPort    = 587
+	DefaultSubject = "[{{ .Type }}] {{ .Reason }}: {{ .InvolvedObject.Kind }} {{ .Namespace }}/{{ .InvolvedObject.Name }}"
+	digestSubject  = "Kubernetes event digest"
+	// dialTimeout bounds the SMTP conversation when the context has no deadline
+	dialTimeout = 30 * time.Second
+)
+
+type Config struct {
+	Host     string
+	Port     int
+	TLS      string
+	Username string
+	Password string
+	From     string
+	To       []string
+	Cc       []string
+	// Subject is a template over the event, with the owner and cluster added
+	Subject string
+	// TLSConfig overrides the TLS settings, the server name defaults to Host
+	TLSConfig *tls.Config
+}
+
+// EmailPublisher sends notifications as multipart plain text and HTML emails over SMTP
+type EmailPublisher struct {
+	Config
+	subjectTemplate *template.Template
+}
+
+func NewEmailPublisher(config Config) (*EmailPublisher, error) {
+	if config.Port == 0 {
+		config.Port = DefaultPort
+	}
+	if config.TLS == "" {
+		config.TLS = StartTLS
+	}
+	if config.Subject == "" {
+		config.Subject = DefaultSubject
+	}
+	subject, err := template.New("subject").Option("missingkey=zero").Parse(config.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject template: %w", err)
+	}
+	return &EmailPublisher{Config: config, subjectTemplate: subject}, nil
+}
+
+func (e *EmailPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	from, recipients, err := e.envelope()
+	if err != nil {
+		return err
+	}
+	message, err := e.buildMessage(notification, time.Now())
+	if err != nil {
+		return err
+	}
+
+	client, err := e.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	defer func() {
+		// Quit fails if the conversation already failed, the connection is closed either way
+		_ = client.Quit()
+		_ = client.Close()
+	}()
+
+	if e.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("smtp server %s does not support authentication", e.Host)
+		}
+		if err := client.Auth(smtp.PlainAuth("", e.Username, e.Password, e.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate to smtp server: %w", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %w", recipient, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message: %w", err)
+	}
+	if _, err := writer.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
+
+// envelope extracts the bare SMTP addresses from the From, To and Cc headers
+func (e *EmailPublisher) envelope() (string, []string, error) {
+	from, err := mail.ParseAddress(e.From)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid sender %q: %w", e.From, err)
+	}
+
+	var recipients []string
+	for _, recipient := range append(append([]string{}, e.To...), e.Cc...) {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid recipient %q: %w", recipient, err)
+		}
+		recipients = append(recipients, address.Address)
+	}
+	if len(recipients) == 0 {
+		return "", nil, fmt.Errorf("no email recipients configured")
+	}
+
+	return from.Address, recipients, nil
+}
+
+func (e *EmailPublisher) dial(ctx context.Context) (*smtp.Client, error) {
+	address := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	tlsConfig := e.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = e.Host
+	}
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if e.TLS == TLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(dialTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	if e.TLS == StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			_ = client.Close()
+			return nil, fmt.Errorf("smtp server %s does not support STARTTLS", e.Host)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			_ = client.Close()
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+// buildMessage renders the RFC 5322 message with a plain text and an HTML alternative
+func (e *EmailPublisher) buildMessage(notification publisher.Notification, now time.Time) ([]byte, error) {
+	subject, err := e.subject(notification)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	var html bytes.Buffer
+	if err := htmlBody.Execute(&html, notification); err != nil {
+		return nil, fmt.Errorf("failed to render html body: %w", err)
+	}
+
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", plainBody(notification)},
+		{"text/html; charset=utf-8", html.String()},
+	} {
+		writer, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoder := quotedprintable.NewWriter(writer)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&message, "%s: %s\r\n", name, value)
+	}
+	header("From", e.From)
+	header("To", strings.Join(e.To, ", "))
+	if len(e.Cc) > 0 {
+		header("Cc", strings.Join(e.Cc, ", "))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageID(e.From))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
+
+func (e *EmailPublisher) subject(notification publisher.Notification) (string, error) {
+	if notification.Event == nil {
+		return digestSubject, nil
+	}
+
+	var subject strings.Builder
+	if err := e.subjectTemplate.Execute(&subject, publisher.NewTemplateData(notification)); err != nil {
+		return "", fmt.Errorf("failed to render subject: %w", err)
+	}
+	// header values must stay on one line
+	return strings.Join(strings.Fields(subject.String()), " "), nil
+}
+
+func plainBody(notification publisher.Notification) string {
+	event := notification.Event
+	if event == nil {
+		return notification.Message
+	}
+
+	var b strings.Builder
+	for _, row := range details(notification) {
+		fmt.Fprintf(&b, "%s: %s\n", row[0], row[1])
+	}
+	fmt.Fprintf(&b, "\n%s\n", event.Message)
+	return b.String()
+}
+
+// details lists the event fields shown in both bodies
+func details(notification publisher.Notification) [][2]string {
+	event := notification.Event
+	rows := [][2]string{
+		{"Reason", event.Reason},
+		{"Type", event.Type},
+		{"Namespace", event.Namespace},
+		{"Object", event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name},
+	}
+	if event.Count > 1 {
+		rows = append(rows, [2]string{"Count", strconv.Itoa(int(event.Count))})
+	}
+	if !event.FirstTimestamp.IsZero() {
+		rows = append(rows, [2]string{"First seen", event.FirstTimestamp.UTC().Format(time.RFC3339)})
+	}
+	if !event.LastTimestamp.IsZero() {
+		rows = append(rows, [2]string{"Last seen", event.LastTimestamp.UTC().Format(time.RFC3339)})
+	}
+	if notification.ClusterName != "" {
+		rows = append(rows, [2]string{"Cluster", notification.ClusterName})
+	}
+	return rows
+}
+
+var htmlBody = htmltemplate.Must(htmltemplate.New("html").Funcs(htmltemplate.FuncMap{
+	"details": details,
+	"color":   color,
+}).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+{{- if .Event }}
+<h2 style="color: {{ color .Event.Type }};">{{ .Event.Reason }}: {{ .Event.InvolvedObject.Kind }} {{ .Event.InvolvedObject.Name }}</h2>
+<p>{{ .Event.Message }}</p>
+<table cellpadding="4" style="border-collapse: collapse;">
+{{- range details . }}
+<tr><th align="left">{{ index . 0 }}</th><td>{{ index . 1 }}</td></tr>
+{{- end }}
+</table>
+{{- else }}
+<pre>{{ .Message }}</pre>
+{{- end }}
+</body>
+</html>
+`))
+
+func color(eventType string) string {
+	if eventType == corev1.EventTypeWarning {
+		return "#c0392b"
+	}
+	return "#2c3e50"
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = strings.Trim(from[at+1:], "> ")
+	}
+
+	random := make([]byte, 12)
+	_, _ = rand.Read(random)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(random), domain)
+}
@@ -0,0 +1,340 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+// received is what the fake server recorded for one message
+type received struct {
+	auth       string
+	from       string
+	recipients []string
+	data       string
+	tls        bool
+}
+
+// fakeSMTP is a minimal SMTP server accepting a single message per connection
+type fakeSMTP struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	implicit  bool
+	messages  chan received
+}
+
+func newFakeSMTP(t *testing.T, implicitTLS bool) (*fakeSMTP, *x509.CertPool) {
+	t.Helper()
+
+	// borrow the self-signed certificate httptest issues for 127.0.0.1
+	certServer := httptest.NewUnstartedServer(nil)
+	certServer.StartTLS()
+	t.Cleanup(certServer.Close)
+	roots := x509.NewCertPool()
+	roots.AddCert(certServer.Certificate())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	server := &fakeSMTP{
+		listener:  listener,
+		tlsConfig: &tls.Config{Certificates: certServer.TLS.Certificates},
+		implicit:  implicitTLS,
+		messages:  make(chan received, 1),
+	}
+	go server.serve(t)
+
+	return server, roots
+}
+
+func (s *fakeSMTP) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTP) serve(t *testing.T) {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	var msg received
+	if s.implicit {
+		conn = tls.Server(conn, s.tlsConfig)
+		msg.tls = true
+	}
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = io.WriteString(conn, line+"\r\n")
+	}
+
+	reply("220 localhost ESMTP fake")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO":
+			reply("250-localhost")
+			if !msg.tls {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN")
+		case "STARTTLS":
+			reply("220 ready to start TLS")
+			conn = tls.Server(conn, s.tlsConfig)
+			reader = bufio.NewReader(conn)
+			msg.tls = true
+		case "AUTH":
+			msg.auth = strings.TrimPrefix(line, "AUTH PLAIN ")
+			reply("235 authenticated")
+		case "MAIL":
+			msg.from = strings.TrimPrefix(line, "MAIL FROM:")
+			reply("250 ok")
+		case "RCPT":
+			msg.recipients = append(msg.recipients, strings.TrimPrefix(line, "RCPT TO:"))
+			reply("250 ok")
+		case "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			msg.data = data.String()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			s.messages <- msg
+			return
+		default:
+			t.Errorf("unexpected smtp command %q", line)
+			reply("500 unknown command")
+		}
+	}
+}
+
+func warningEvent() *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web-0"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting <failed> container",
+		Type:           corev1.EventTypeWarning,
+		Count:          3,
+	}
+}
+
+// parts decodes the multipart/alternative body into content type and text
+func parts(t *testing.T, data string) (*mail.Message, map[string]string) {
+	t.Helper()
+
+	message, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("unexpected content type %q: %v", message.Header.Get("Content-Type"), err)
+	}
+
+	bodies := map[string]string{}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		// NextPart decodes quoted-printable transparently, line endings are normalised for comparison
+		content, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[contentType] = strings.ReplaceAll(string(content), "\r\n", "\n")
+	}
+	return message, bodies
+}
+
+func TestEmailPublisherStartTLS(t *testing.T) {
+	server, roots := newFakeSMTP(t, false)
+	p, err := NewEmailPublisher(Config{
+		Host:      "127.0.0.1",
+		Port:      server.port(),
+		Username:  "notifier",
+		Password:  "hunter2",
+		From:      "Notifier <notifier@example.com>",
+		To:        []string{"On Call <oncall@example.com>"},
+		Cc:        []string{"team@example.com"},
+		TLSConfig: &tls.Config{RootCAs: roots},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.Send(context.Background(), publisher.Notification{Event: warningEvent(), ClusterName: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := <-server.messages
+	if !msg.tls {
+		t.Error("expected the connection to be upgraded with STARTTLS")
+	}
+	if auth, _ := base64.StdEncoding.DecodeString(msg.auth); string(auth) != "\x00notifier\x00hunter2" {
+		t.Errorf("unexpected credentials %q", auth)
+	}
+	if msg.from != "<notifier@example.com>" {
+		t.Errorf("unexpected sender %q", msg.from)
+	}
+	if strings.Join(msg.recipients, ",") != "<oncall@example.com>,<team@example.com>" {
+		t.Errorf("unexpected recipients %v", msg.recipients)
+	}
+
+	message, bodies := parts(t, msg.data)
+	if got := message.Header.Get("Subject"); got != "[Warning] BackOff: Pod default/web-0" {
+		t.Errorf("unexpected subject %q", got)
+	}
+	if message.Header.Get("Cc") != "team@example.com" {
+		t.Errorf("unexpected Cc header %q", message.Header.Get("Cc"))
+	}
+
+	plain := bodies["text/plain"]
+	if !strings.Contains(plain, "Object: Pod/web-0\n") || !strings.Contains(plain, "Cluster: prod\n") ||
+		!strings.Contains(plain, "Back-off restarting <failed> container") {
+		t.Errorf("unexpected plain text body %q", plain)
+	}
+	html := bodies["text/html"]
+	if !strings.Contains(html, "Back-off restarting &lt;failed&gt; container") || !strings.Contains(html, "<th align=\"left\">Count</th><td>3</td>") {
+		t.Errorf("unexpected html body %q", html)
+	}
+}
+
+func TestEmailPublisherImplicitTLS(t *testing.T) {
+	server, roots := newFakeSMTP(t, true)
+	p, err := NewEmailPublisher(Config{
+		Host:      "127.0.0.1",
+		Port:      server.port(),
+		TLS:       TLS,
+		From:      "notifier@example.com",
+		To:        []string{"oncall@example.com"},
+		Subject:   "{{ .Reason }} in {{ .Namespace }} on {{ .ClusterName }}",
+		TLSConfig: &tls.Config{RootCAs: roots},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Send(context.Background(), publisher.Notification{Event: warningEvent(), ClusterName: "prod"}); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := <-server.messages
+	if msg.auth != "" {
+		t.Errorf("expected no authentication without a username, got %q", msg.auth)
+	}
+	message, _ := parts(t, msg.data)
+	if got := message.Header.Get("Subject"); got != "BackOff in default on prod" {
+		t.Errorf("unexpected subject %q", got)
+	}
+}
+
+func TestEmailPublisherDigest(t *testing.T) {
+	p, err := NewEmailPublisher(Config{From: "notifier@example.com", To: []string{"oncall@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := p.buildMessage(publisher.Notification{Message: "*Digest*\n3 events"}, metav1.Now().Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message, bodies := parts(t, string(data))
+	if got := message.Header.Get("Subject"); got != digestSubject {
+		t.Errorf("unexpected subject %q", got)
+	}
+	if bodies["text/plain"] != "*Digest*\n3 events" || !strings.Contains(bodies["text/html"], "<pre>*Digest*\n3 events</pre>") {
+		t.Errorf("unexpected bodies %q", bodies)
+	}
+}
+
+func TestEmailPublisherInvalidSubject(t *testing.T) {
+	if _, err := NewEmailPublisher(Config{Subject: "{{ .Reason"}); err == nil {
+		t.Error("expected an error for an invalid subject template")
+	}
+}
+
+func TestEmailPublisherRequiresStartTLS(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		reader := bufio.NewReader(conn)
+		_, _ = io.WriteString(conn, "220 localhost ESMTP\r\n")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if strings.HasPrefix(line, "EHLO") {
+				_, _ = io.WriteString(conn, "250 localhost\r\n")
+			} else {
+				_, _ = io.WriteString(conn, "221 bye\r\n")
+			}
+		}
+	}()
+
+	p, err := NewEmailPublisher(Config{
+		Host: "127.0.0.1",
+		Port: listener.Addr().(*net.TCPAddr).Port,
+		From: "notifier@example.com",
+		To:   []string{"oncall@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.Send(context.Background(), publisher.Notification{Event: warningEvent()})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("expected a STARTTLS error, got %v", err)
+	}
+}
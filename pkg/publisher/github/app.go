@@ -0,0 +1,210 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+// App authenticates as a GitHub App installation
+type App struct {
+	ID int64
+	// InstallationID is looked up per repository if zero
+	InstallationID int64
+	// PrivateKey is the PEM encoded key of the app
+	PrivateKey string
+}
+
+const (
+	// tokenExpiryMargin renews installation tokens before they expire
+	tokenExpiryMargin = time.Minute
+	// appIdleTimeout drops the apps unused for longer than installation tokens last
+	appIdleTimeout = time.Hour
+)
+
+type installationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// appTokens issues installation access tokens, reusing them until they are about to expire
+type appTokens struct {
+	app     App
+	key     *rsa.PrivateKey
+	baseURL string
+
+	mu            sync.Mutex
+	tokens        map[int64]installationToken
+	installations map[string]int64
+
+	// lastUse is guarded by the mutex of the AppTokenCache
+	lastUse time.Time
+}
+
+// AppTokenCache keeps the installation tokens of apps across publishers, so that
+// a publisher created on every reconcile does not request a new token each time.
+// Apps unused until their tokens expired, such as those of rotated keys or deleted
+// notifiers, are dropped. The zero value is ready to use.
+type AppTokenCache struct {
+	mu   sync.Mutex
+	apps map[appKey]*appTokens
+}
+
+// appKey identifies an app by its credentials, a rotated private key gets new tokens
+type appKey struct {
+	baseURL        string
+	id             int64
+	installationID int64
+	privateKey     [sha256.Size]byte
+}
+
+// tokens returns the token source of app, creating it on first use
+func (c *AppTokenCache) tokens(app App, baseURL string) (*appTokens, error) {
+	key := appKey{
+		baseURL:        baseURL,
+		id:             app.ID,
+		installationID: app.InstallationID,
+		privateKey:     sha256.Sum256([]byte(app.PrivateKey)),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, tokens := range c.apps {
+		if now().Sub(tokens.lastUse) > appIdleTimeout {
+			delete(c.apps, k)
+		}
+	}
+	if tokens, ok := c.apps[key]; ok {
+		tokens.lastUse = now()
+		return tokens, nil
+	}
+	tokens, err := newAppTokens(app, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if c.apps == nil {
+		c.apps = make(map[appKey]*appTokens)
+	}
+	tokens.lastUse = now()
+	c.apps[key] = tokens
+	return tokens, nil
+}
+
+func newAppTokens(app App, baseURL string) (*appTokens, error) {
+	block, _ := pem.Decode([]byte(app.PrivateKey))
+	if block == nil {
+		return nil, errors.New("no PEM block found in github app private key")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, pkcs8Err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		var ok bool
+		if key, ok = parsed.(*rsa.PrivateKey); pkcs8Err != nil || !ok {
+			return nil, fmt.Errorf("invalid github app private key: %w", err)
+		}
+	}
+	return &appTokens{
+		app:           app,
+		key:           key,
+		baseURL:       baseURL,
+		tokens:        map[int64]installationToken{},
+		installations: map[string]int64{},
+	}, nil
+}
+
+// token returns an installation token allowed to access repository, in owner/name form
+func (a *appTokens) token(ctx context.Context, repository string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	installation, err := a.installation(ctx, repository)
+	if err != nil {
+		return "", err
+	}
+	if token, ok := a.tokens[installation]; ok && time.Until(token.ExpiresAt) > tokenExpiryMargin {
+		return token.Token, nil
+	}
+
+	body, err := a.request(ctx, http.MethodPost, "/app/installations/"+strconv.FormatInt(installation, 10)+"/access_tokens")
+	if err != nil {
+		return "", fmt.Errorf("failed to create github installation token: %w", err)
+	}
+	var token installationToken
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("invalid github installation token: %w", err)
+	}
+	a.tokens[installation] = token
+	return token.Token, nil
+}
+
+func (a *appTokens) installation(ctx context.Context, repository string) (int64, error) {
+	if a.app.InstallationID != 0 {
+		return a.app.InstallationID, nil
+	}
+	if id, ok := a.installations[repository]; ok {
+		return id, nil
+	}
+
+	body, err := a.request(ctx, http.MethodGet, "/repos/"+repository+"/installation")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find the github app installation of %s: %w", repository, err)
+	}
+	var installation struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal(body, &installation); err != nil {
+		return 0, fmt.Errorf("invalid github app installation: %w", err)
+	}
+	a.installations[repository] = installation.ID
+	return installation.ID, nil
+}
+
+// request calls the API as the app itself
+func (a *appTokens) request(ctx context.Context, method, path string) ([]byte, error) {
+	jwt, err := a.jwt()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	setHeader(req.Header, jwt)
+	return publisher.Do(req)
+}
+
+// jwt signs the short-lived token authenticating the app, backdated against clock drift
+func (a *appTokens) jwt() (string, error) {
+	issued := now().Add(-time.Minute)
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]any{
+		"iat": issued.Unix(),
+		"exp": issued.Add(10 * time.Minute).Unix(),
+		"iss": strconv.FormatInt(a.app.ID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign github app token: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
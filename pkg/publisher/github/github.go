@@ -0,0 +1,257 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+const (
+	DefaultBaseURL = "https://api.github.com"
+	DefaultTitle   = "{{ .Reason }}: {{ .InvolvedObject.Kind }} {{ .InvolvedObject.Namespace }}/{{ .InvolvedObject.Name }}"
+
+	// fingerprintPrefix labels issues with the fingerprint of their condition
+	fingerprintPrefix = "k8s-fingerprint-"
+	digestTitle       = "Kubernetes event digest"
+	apiVersion        = "2022-11-28"
+)
+
+// Issue field limits of GitHub
+const (
+	maxTitle = 256
+	maxBody  = 65536
+)
+
+var (
+	now = time.Now
+
+	repositoryPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+)
+
+// Config defines the repositories issues are filed in and how to authenticate
+type Config struct {
+	// BaseURL of the REST API, such as https://github.example.com/api/v3 for GitHub Enterprise Server
+	BaseURL string
+	// Token authenticates requests, unless App is set
+	Token string
+	App   *App
+	// Repository is a template of the owner/name repository issues are filed in,
+	// evaluated against the event and the annotations of its owner
+	Repository string
+	// DigestRepository receives digests, which are not filed if empty
+	DigestRepository string
+	// Title and Body are templates over the event, the body defaults to the notification message
+	Title  string
+	Body   string
+	Labels []string
+}
+
+// TemplateData is what the repository, title and body templates are evaluated
+// against: the event fields and the annotations of the owning workload
+type TemplateData struct {
+	publisher.TemplateData
+	OwnerAnnotations map[string]string
+}
+
+// AnnotationsFunc returns the annotations of the owner of the notification event
+type AnnotationsFunc func(ctx context.Context, notification publisher.Notification) map[string]string
+
+// GitHubPublisher opens an issue per recurring condition. Events sharing the
+// fingerprint label of an open issue are added to it as comments.
+type GitHubPublisher struct {
+	config      Config
+	repository  *template.Template
+	title       *template.Template
+	body        *template.Template
+	annotations AnnotationsFunc
+	app         *appTokens
+}
+
+type issue struct {
+	Number int `json:"number"`
+}
+
+// NewGitHubPublisher creates a publisher, taking the installation tokens of an app
+// from tokens. A nil cache keeps them for the lifetime of the publisher only.
+func NewGitHubPublisher(config Config, annotations AnnotationsFunc, tokens *AppTokenCache) (*GitHubPublisher, error) {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultBaseURL
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+	if config.Title == "" {
+		config.Title = DefaultTitle
+	}
+	if config.Token == "" && config.App == nil {
+		return nil, errors.New("a github token or app is required")
+	}
+	if config.DigestRepository != "" && !repositoryPattern.MatchString(config.DigestRepository) {
+		return nil, fmt.Errorf("invalid digest repository %q, expected owner/name", config.DigestRepository)
+	}
+
+	g := &GitHubPublisher{config: config, annotations: annotations}
+	var err error
+	if g.repository, err = parse("repository", config.Repository); err != nil {
+		return nil, err
+	}
+	if g.title, err = parse("title", config.Title); err != nil {
+		return nil, err
+	}
+	if config.Body != "" {
+		if g.body, err = parse("body", config.Body); err != nil {
+			return nil, err
+		}
+	}
+	if config.App != nil {
+		if tokens == nil {
+			tokens = &AppTokenCache{}
+		}
+		if g.app, err = tokens.tokens(*config.App, config.BaseURL); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+func parse(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return t, nil
+}
+
+func (g *GitHubPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	if notification.Event == nil {
+		if g.config.DigestRepository == "" {
+			return nil
+		}
+		return g.create(ctx, g.config.DigestRepository, map[string]any{
+			"title":  digestTitle,
+			"body":   publisher.Truncate(notification.Message, maxBody),
+			"labels": append([]string{}, g.config.Labels...),
+		})
+	}
+
+	data := TemplateData{TemplateData: publisher.NewTemplateData(notification)}
+	if g.annotations != nil {
+		data.OwnerAnnotations = g.annotations(ctx, notification)
+	}
+	repository, err := render(g.repository, data)
+	if err != nil {
+		return err
+	}
+	repository = strings.TrimSpace(repository)
+	if !repositoryPattern.MatchString(repository) {
+		return fmt.Errorf("repository template rendered %q, expected owner/name", repository)
+	}
+
+	fingerprint := fingerprintPrefix + publisher.Fingerprint(notification)
+	issues, err := g.openIssues(ctx, repository, fingerprint)
+	if err != nil {
+		return err
+	}
+	if len(issues) > 0 {
+		comment := fmt.Sprintf("Occurred again (count %d): %s", notification.Event.Count, notification.Message)
+		return g.comment(ctx, repository, issues[0].Number, comment)
+	}
+
+	title, err := render(g.title, data)
+	if err != nil {
+		return err
+	}
+	body := notification.Message
+	if g.body != nil {
+		if body, err = render(g.body, data); err != nil {
+			return err
+		}
+	}
+	return g.create(ctx, repository, map[string]any{
+		"title":  publisher.Truncate(strings.TrimSpace(title), maxTitle),
+		"body":   publisher.Truncate(body, maxBody),
+		"labels": append(append([]string{}, g.config.Labels...), fingerprint),
+	})
+}
+
+func render(t *template.Template, data TemplateData) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", t.Name(), err)
+	}
+	return b.String(), nil
+}
+
+// openIssues lists the open issues of repository carrying label, most recent first
+func (g *GitHubPublisher) openIssues(ctx context.Context, repository, label string) ([]issue, error) {
+	query := url.Values{"labels": {label}, "state": {"open"}, "per_page": {"1"}}
+	body, err := g.get(ctx, repository, "/repos/"+repository+"/issues?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list github issues of %s: %w", repository, err)
+	}
+	var issues []issue
+	if err := json.Unmarshal(body, &issues); err != nil {
+		return nil, fmt.Errorf("invalid github issues response: %w", err)
+	}
+	return issues, nil
+}
+
+func (g *GitHubPublisher) create(ctx context.Context, repository string, payload map[string]any) error {
+	if _, err := g.post(ctx, repository, "/repos/"+repository+"/issues", payload); err != nil {
+		return fmt.Errorf("failed to open github issue in %s: %w", repository, err)
+	}
+	return nil
+}
+
+func (g *GitHubPublisher) comment(ctx context.Context, repository string, number int, body string) error {
+	path := "/repos/" + repository + "/issues/" + strconv.Itoa(number) + "/comments"
+	if _, err := g.post(ctx, repository, path, map[string]string{"body": publisher.Truncate(body, maxBody)}); err != nil {
+		return fmt.Errorf("failed to comment on github issue %s#%d: %w", repository, number, err)
+	}
+	return nil
+}
+
+func (g *GitHubPublisher) get(ctx context.Context, repository, path string) ([]byte, error) {
+	token, err := g.token(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.config.BaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	setHeader(req.Header, token)
+	return publisher.Do(req)
+}
+
+func (g *GitHubPublisher) post(ctx context.Context, repository, path string, payload any) ([]byte, error) {
+	token, err := g.token(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	setHeader(header, token)
+	return publisher.PostJSON(ctx, g.config.BaseURL+path, payload, header)
+}
+
+// token returns the configured token, or an installation token of the app allowed to access repository
+func (g *GitHubPublisher) token(ctx context.Context, repository string) (string, error) {
+	if g.app == nil {
+		return g.config.Token, nil
+	}
+	return g.app.token(ctx, repository)
+}
+
+func setHeader(header http.Header, token string) {
+	header.Set("Accept", "application/vnd.github+json")
+	header.Set("X-GitHub-Api-Version", apiVersion)
+	header.Set("Authorization", "Bearer "+token)
+}
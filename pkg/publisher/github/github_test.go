@@ -0,0 +1,250 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func failedRollout(name string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: name},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Type:           corev1.EventTypeWarning,
+		Count:          2,
+	}
+}
+
+// fakeGitHub keeps the issues of every repository in memory
+type fakeGitHub struct {
+	mu       sync.Mutex
+	issues   map[string][]map[string]any
+	comments map[string][]string
+	// authorize checks the authorization header of repository requests
+	authorize func(repository, authorization string) bool
+}
+
+func newFakeGitHub(t *testing.T, prefix string, authorize func(repository, authorization string) bool) (*fakeGitHub, *http.ServeMux, *httptest.Server) {
+	f := &fakeGitHub{issues: map[string][]map[string]any{}, comments: map[string][]string{}, authorize: authorize}
+	mux := http.NewServeMux()
+	mux.HandleFunc(prefix+"/repos/{owner}/{repo}/issues", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		repository := r.PathValue("owner") + "/" + r.PathValue("repo")
+		if r.Header.Get("X-GitHub-Api-Version") != apiVersion || !f.authorize(repository, r.Header.Get("Authorization")) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			found := []map[string]any{}
+			for _, issue := range f.issues[repository] {
+				for _, label := range issue["labels"].([]any) {
+					if label == r.URL.Query().Get("labels") && r.URL.Query().Get("state") == "open" {
+						found = append(found, issue)
+					}
+				}
+			}
+			_ = json.NewEncoder(w).Encode(found)
+			return
+		}
+		var issue map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&issue); err != nil {
+			t.Error(err)
+		}
+		issue["number"] = len(f.issues[repository]) + 1
+		f.issues[repository] = append(f.issues[repository], issue)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(issue)
+	})
+	mux.HandleFunc("POST "+prefix+"/repos/{owner}/{repo}/issues/{number}/comments", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		var comment map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&comment)
+		key := r.PathValue("owner") + "/" + r.PathValue("repo") + "#" + r.PathValue("number")
+		f.comments[key] = append(f.comments[key], comment["body"])
+		w.WriteHeader(http.StatusCreated)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return f, mux, server
+}
+
+func TestGitHubPublisherSend(t *testing.T) {
+	f, _, server := newFakeGitHub(t, "/api/v3", func(_, authorization string) bool {
+		return authorization == "Bearer token"
+	})
+
+	annotations := func(_ context.Context, notification publisher.Notification) map[string]string {
+		if notification.Owner == "Deployment/cart" {
+			return map[string]string{"example.com/repository": "acme/cart"}
+		}
+		return nil
+	}
+	p, err := NewGitHubPublisher(Config{
+		BaseURL:          server.URL + "/api/v3/",
+		Token:            "token",
+		Repository:       `{{ or (index .OwnerAnnotations "example.com/repository") "acme/platform" }}`,
+		DigestRepository: "acme/platform",
+		Labels:           []string{"kubernetes"},
+	}, annotations, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, n := range []publisher.Notification{
+		{Message: "cart-0 is crash looping", Event: failedRollout("cart-0"), Owner: "Deployment/cart"},
+		{Message: "cart-1 is crash looping", Event: failedRollout("cart-1"), Owner: "Deployment/cart"},
+		{Message: "db-0 is crash looping", Event: failedRollout("db-0"), Owner: "StatefulSet/db"},
+		{Message: "3 events"},
+	} {
+		if err := p.Send(ctx, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cart := f.issues["acme/cart"]
+	if len(cart) != 1 || cart[0]["title"] != "BackOff: Pod shop/cart-0" || cart[0]["body"] != "cart-0 is crash looping" {
+		t.Fatalf("expected one issue in the annotated repository, got %v", cart)
+	}
+	if labels := cart[0]["labels"].([]any); len(labels) != 2 || labels[0] != "kubernetes" || !strings.HasPrefix(labels[1].(string), fingerprintPrefix) {
+		t.Errorf("unexpected labels %v", labels)
+	}
+	if comments := f.comments["acme/cart#1"]; len(comments) != 1 || !strings.Contains(comments[0], "cart-1 is crash looping") {
+		t.Errorf("expected the second pod as a comment, got %v", comments)
+	}
+	platform := f.issues["acme/platform"]
+	if len(platform) != 2 || platform[1]["title"] != digestTitle {
+		t.Errorf("expected the unannotated owner and the digest in the default repository, got %v", platform)
+	}
+}
+
+func TestGitHubPublisherApp(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var mu sync.Mutex
+	tokensIssued := 0
+	_, mux, server := newFakeGitHub(t, "", func(repository, authorization string) bool {
+		return authorization == "Bearer installation-7" && repository == "acme/cart"
+	})
+
+	verifyJWT := func(r *http.Request) bool {
+		parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+		if len(parts) != 3 {
+			return false
+		}
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature) != nil {
+			return false
+		}
+		claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		return strings.Contains(string(claims), `"iss":"42"`)
+	}
+	mux.HandleFunc("GET /repos/acme/cart/installation", func(w http.ResponseWriter, r *http.Request) {
+		if !verifyJWT(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":7}`))
+	})
+	mux.HandleFunc("POST /app/installations/7/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		if !verifyJWT(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		tokensIssued++
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"token":"installation-7","expires_at":%q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+	})
+
+	// publishers are created on every reconcile, the cache keeps the token across them
+	tokens := &AppTokenCache{}
+	notification := publisher.Notification{Message: "cart-0 is crash looping", Event: failedRollout("cart-0"), Owner: "Deployment/cart"}
+	for range 2 {
+		p, err := NewGitHubPublisher(Config{
+			BaseURL:    server.URL,
+			App:        &App{ID: 42, PrivateKey: string(privateKey)},
+			Repository: "acme/cart",
+		}, nil, tokens)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Send(context.Background(), notification); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if tokensIssued != 1 {
+		t.Errorf("expected the installation token to be reused, %d were issued", tokensIssued)
+	}
+
+	if _, err := NewGitHubPublisher(Config{Repository: "acme/cart"}, nil, nil); err == nil {
+		t.Error("expected a token or app to be required")
+	}
+	if _, err := NewGitHubPublisher(Config{Token: "token", Repository: "acme/cart", App: &App{ID: 42, PrivateKey: "invalid"}}, nil, nil); err == nil {
+		t.Error("expected invalid private keys to be rejected")
+	}
+}
+
+func TestAppTokenCacheDropsIdleApps(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	newApp := func(id int64) App {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return App{ID: id, PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))}
+	}
+	rotated, current := newApp(42), newApp(42)
+
+	cache := &AppTokenCache{}
+	first, err := cache.tokens(rotated, "https://api.github.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock = clock.Add(30 * time.Minute)
+	if again, _ := cache.tokens(rotated, "https://api.github.com"); again != first {
+		t.Error("expected the app to be reused while in use")
+	}
+
+	clock = clock.Add(appIdleTimeout + time.Minute)
+	if _, err := cache.tokens(current, "https://api.github.com"); err != nil {
+		t.Fatal(err)
+	}
+	if len(cache.apps) != 1 {
+		t.Errorf("expected the idle app to be dropped, %d are cached", len(cache.apps))
+	}
+}
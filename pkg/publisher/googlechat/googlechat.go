@@ -0,0 +1,164 @@
+package googlechat
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+const (
+	// maxText keeps messages well below the 32KB Google Chat message limit
+	maxText   = 4000
+	maxHeader = 200
+)
+
+const (
+	colorWarning = "#E67E22"
+	colorNormal  = "#2ECC71"
+)
+
+// Message is a Google Chat message with cards,
+// see https://developers.google.com/workspace/chat/api/reference/rest/v1/cards
+type Message struct {
+	Text    string  `json:"text,omitempty"`
+	CardsV2 []Card  `json:"cardsV2,omitempty"`
+	Thread  *Thread `json:"thread,omitempty"`
+}
+
+type Thread struct {
+	ThreadKey string `json:"threadKey"`
+}
+
+type Card struct {
+	CardID string   `json:"cardId"`
+	Card   CardBody `json:"card"`
+}
+
+type CardBody struct {
+	Header   *Header   `json:"header,omitempty"`
+	Sections []Section `json:"sections"`
+}
+
+type Header struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle,omitempty"`
+}
+
+type Section struct {
+	Header  string   `json:"header,omitempty"`
+	Widgets []Widget `json:"widgets"`
+}
+
+type Widget struct {
+	TextParagraph *TextParagraph `json:"textParagraph,omitempty"`
+	DecoratedText *DecoratedText `json:"decoratedText,omitempty"`
+}
+
+type TextParagraph struct {
+	Text string `json:"text"`
+}
+
+type DecoratedText struct {
+	TopLabel string `json:"topLabel"`
+	Text     string `json:"text"`
+}
+
+// GoogleChatPublisher posts card messages to a space through its incoming webhook.
+// Events of the same object are replied to in one thread.
+type GoogleChatPublisher struct {
+	WebhookURL string
+}
+
+func NewGoogleChatPublisher(webhookURL string) *GoogleChatPublisher {
+	return &GoogleChatPublisher{WebhookURL: webhookURL}
+}
+
+func (g *GoogleChatPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	message := buildMessage(notification)
+
+	endpoint := g.WebhookURL
+	if message.Thread != nil {
+		webhookURL, err := url.Parse(g.WebhookURL)
+		if err != nil {
+			return fmt.Errorf("invalid google chat webhook: %w", err)
+		}
+		query := webhookURL.Query()
+		// the first message of an object starts the thread, later ones reply to it
+		query.Set("messageReplyOption", "REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD")
+		webhookURL.RawQuery = query.Encode()
+		endpoint = webhookURL.String()
+	}
+
+	if _, err := publisher.PostJSON(ctx, endpoint, message, nil); err != nil {
+		return fmt.Errorf("failed to send google chat message: %w", err)
+	}
+	return nil
+}
+
+func buildMessage(notification publisher.Notification) Message {
+	event := notification.Event
+	if event == nil {
+		return Message{Text: publisher.Truncate(notification.Message, maxText)}
+	}
+
+	title := fmt.Sprintf("%s: %s %s", event.Reason, event.InvolvedObject.Kind, event.InvolvedObject.Name)
+	subtitle := event.Namespace
+	if notification.ClusterName != "" {
+		subtitle = notification.ClusterName + " / " + subtitle
+	}
+
+	details := []Widget{
+		decoratedText("Namespace", event.Namespace),
+		decoratedText("Kind", event.InvolvedObject.Kind),
+		decoratedText("Name", event.InvolvedObject.Name),
+		decoratedText("Type", event.Type),
+	}
+	if event.Count > 1 {
+		details = append(details, decoratedText("Count", strconv.Itoa(int(event.Count))))
+	}
+
+	message := fmt.Sprintf(`<font color="%s">%s</font>`, color(event.Type), html.EscapeString(publisher.Truncate(event.Message, maxText)))
+	return Message{
+		Text: publisher.Truncate(title, maxHeader),
+		CardsV2: []Card{{
+			CardID: "event",
+			Card: CardBody{
+				Header: &Header{Title: publisher.Truncate(title, maxHeader), Subtitle: publisher.Truncate(subtitle, maxHeader)},
+				Sections: []Section{
+					{Widgets: []Widget{{TextParagraph: &TextParagraph{Text: message}}}},
+					{Header: "Details", Widgets: details},
+				},
+			},
+		}},
+		Thread: &Thread{ThreadKey: ThreadKey(notification)},
+	}
+}
+
+// ThreadKey identifies the thread of the notification object, prefixed by the cluster if known
+func ThreadKey(notification publisher.Notification) string {
+	key := publisher.ObjectKey(notification.Event)
+	if notification.ClusterName != "" {
+		key = notification.ClusterName + "/" + key
+	}
+	return key
+}
+
+func decoratedText(label, value string) Widget {
+	if value == "" {
+		value = "-"
+	}
+	return Widget{DecoratedText: &DecoratedText{TopLabel: label, Text: html.EscapeString(publisher.Truncate(value, maxHeader))}}
+}
+
+func color(eventType string) string {
+	if eventType == corev1.EventTypeWarning {
+		return colorWarning
+	}
+	return colorNormal
+}
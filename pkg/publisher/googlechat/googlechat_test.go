@@ -0,0 +1,116 @@
+package googlechat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+type request struct {
+	query   url.Values
+	message Message
+}
+
+func fakeSpace(t *testing.T) (*httptest.Server, *[]request) {
+	t.Helper()
+
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message Message
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("failed to decode message: %v", err)
+		}
+		requests = append(requests, request{query: r.URL.Query(), message: message})
+		_, _ = w.Write([]byte(`{"name": "spaces/AAA/messages/BBB"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func podEvent(reason, message string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "cart-0"},
+		Reason:         reason,
+		Message:        message,
+		Type:           corev1.EventTypeWarning,
+	}
+}
+
+func TestGoogleChatPublisherThreadsPerObject(t *testing.T) {
+	server, requests := fakeSpace(t)
+	p := NewGoogleChatPublisher(server.URL + "/v1/spaces/AAA/messages?key=k&token=t")
+
+	ctx := context.Background()
+	for _, event := range []*corev1.Event{
+		podEvent("BackOff", "Back-off restarting failed container"),
+		podEvent("Unhealthy", "Readiness probe failed: <html> 503"),
+	} {
+		if err := p.Send(ctx, publisher.Notification{Event: event, ClusterName: "gke-eu"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(*requests))
+	}
+	for _, r := range *requests {
+		if r.query.Get("key") != "k" || r.query.Get("token") != "t" {
+			t.Errorf("expected the webhook credentials to be kept, got %v", r.query)
+		}
+		if r.query.Get("messageReplyOption") != "REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD" {
+			t.Errorf("expected replies to the object thread, got %v", r.query)
+		}
+		if r.message.Thread == nil || r.message.Thread.ThreadKey != "gke-eu/shop/Pod/cart-0" {
+			t.Errorf("unexpected thread %+v", r.message.Thread)
+		}
+	}
+
+	card := (*requests)[1].message.CardsV2[0].Card
+	if card.Header.Title != "Unhealthy: Pod cart-0" || card.Header.Subtitle != "gke-eu / shop" {
+		t.Errorf("unexpected header %+v", card.Header)
+	}
+	if got := card.Sections[0].Widgets[0].TextParagraph.Text; got != `<font color="#E67E22">Readiness probe failed: &lt;html&gt; 503</font>` {
+		t.Errorf("expected the message to be escaped, got %q", got)
+	}
+	if details := card.Sections[1].Widgets; len(details) != 4 || details[0].DecoratedText.Text != "shop" {
+		t.Errorf("unexpected details %+v", details)
+	}
+}
+
+func TestGoogleChatPublisherDigest(t *testing.T) {
+	server, requests := fakeSpace(t)
+
+	if err := NewGoogleChatPublisher(server.URL).Send(context.Background(), publisher.Notification{Message: "*Digest*"}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := (*requests)[0]
+	if r.message.Text != "*Digest*" || r.message.Thread != nil || len(r.message.CardsV2) != 0 {
+		t.Errorf("expected a plain unthreaded message, got %+v", r.message)
+	}
+	if r.query.Has("messageReplyOption") {
+		t.Errorf("unexpected reply option %v", r.query)
+	}
+}
+
+func TestGoogleChatPublisherError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": {"code": 400, "message": "Invalid JSON payload"}}`))
+	}))
+	defer server.Close()
+
+	if err := NewGoogleChatPublisher(server.URL).Send(context.Background(), publisher.Notification{Event: podEvent("BackOff", "x")}); err == nil {
+		t.Error("expected an error for a rejected message")
+	}
+}
@@ -0,0 +1,86 @@
+package gotify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/example/notifier/pkg/publisher"
+	"github.com/example/notifier/pkg/publisher/push"
+)
+
+const (
+	DefaultPriority = 5
+
+	// Priorities range from silent to the highest, 8 and above override do not disturb on Android
+	minPriority = 0
+	maxPriority = 10
+)
+
+// Config defines the server and application notifications are sent as
+type Config struct {
+	URL string
+	// Token is the token of the application notifications are sent as
+	Token      string
+	Priorities []publisher.Rule[int]
+	// DefaultPriority applies if no rule matches, zero being a valid priority
+	DefaultPriority int
+	// ClickURL is a template of the page opened when the notification is tapped
+	ClickURL string
+}
+
+type message struct {
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras,omitempty"`
+}
+
+// GotifyPublisher sends messages of an application to a Gotify server
+type GotifyPublisher struct {
+	config     Config
+	priorities push.Priorities
+	click      *push.ClickURL
+}
+
+func NewGotifyPublisher(config Config) (*GotifyPublisher, error) {
+	config.URL = strings.TrimSuffix(config.URL, "/")
+	if config.URL == "" || config.Token == "" {
+		return nil, errors.New("a gotify URL and application token are required")
+	}
+
+	priorities := push.Priorities{Rules: config.Priorities, Default: config.DefaultPriority}
+	if err := priorities.Validate(minPriority, maxPriority); err != nil {
+		return nil, fmt.Errorf("gotify: %w", err)
+	}
+	click, err := push.ParseClickURL(config.ClickURL)
+	if err != nil {
+		return nil, err
+	}
+	return &GotifyPublisher{config: config, priorities: priorities, click: click}, nil
+}
+
+func (g *GotifyPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	click, err := g.click.Render(notification)
+	if err != nil {
+		return err
+	}
+
+	msg := message{
+		Title:    push.Title(notification),
+		Message:  notification.Message,
+		Priority: g.priorities.Of(notification),
+	}
+	if click != "" {
+		msg.Extras = map[string]any{
+			"client::notification": map[string]any{"click": map[string]string{"url": click}},
+		}
+	}
+
+	header := http.Header{}
+	header.Set("X-Gotify-Key", g.config.Token)
+	_, err = publisher.PostJSON(ctx, g.config.URL+"/message", msg, header)
+	return err
+}
@@ -0,0 +1,69 @@
+package gotify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func TestGotifyPublisherSend(t *testing.T) {
+	var received []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gotify/message" || r.Header.Get("X-Gotify-Key") != "app-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var m map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			t.Error(err)
+		}
+		received = append(received, m)
+	}))
+	defer server.Close()
+
+	p, err := NewGotifyPublisher(Config{
+		URL:        server.URL + "/gotify/",
+		Token:      "app-token",
+		Priorities: []publisher.Rule[int]{{Reason: "Pulled", Value: 0}, {Type: corev1.EventTypeWarning, Value: 8}},
+		ClickURL:   "https://k8s.example.com/{{ .InvolvedObject.Name }}",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	warning := &corev1.Event{InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "pi-1"}, Reason: "NodeNotReady", Type: corev1.EventTypeWarning}
+	pulled := &corev1.Event{InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web"}, Reason: "Pulled", Type: corev1.EventTypeNormal}
+	for _, n := range []publisher.Notification{
+		{Message: "pi-1 is not ready", Event: warning},
+		{Message: "image pulled", Event: pulled},
+	} {
+		if err := p.Send(context.Background(), n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("expected two messages, got %v", received)
+	}
+	first := received[0]
+	if first["title"] != "NodeNotReady: Node pi-1" || first["message"] != "pi-1 is not ready" || first["priority"] != float64(8) {
+		t.Errorf("unexpected message %v", first)
+	}
+	click := first["extras"].(map[string]any)["client::notification"].(map[string]any)["click"].(map[string]any)
+	if click["url"] != "https://k8s.example.com/pi-1" {
+		t.Errorf("unexpected click url %v", click)
+	}
+	if received[1]["priority"] != float64(0) {
+		t.Errorf("expected the silent priority of the matching rule, got %v", received[1]["priority"])
+	}
+
+	if _, err := NewGotifyPublisher(Config{URL: server.URL}); err == nil {
+		t.Error("expected an application token to be required")
+	}
+}
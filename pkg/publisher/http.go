@@ -0,0 +1,72 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HTTPError is returned when an endpoint answers with a non-2xx status
+type HTTPError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("unexpected response: %s (status: %d)", string(e.Body), e.StatusCode)
+}
+
+// PostJSON posts payload encoded as JSON to url and returns the response body.
+// Non-2xx responses are returned as *HTTPError.
+func PostJSON(ctx context.Context, url string, payload any, header http.Header) ([]byte, error) {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return Do(req)
+}
+
+// Do sends the request and returns the response body.
+// Non-2xx responses are returned as *HTTPError.
+func Do(req *http.Request) ([]byte, error) {
+	return DoWith(http.DefaultClient, req)
+}
+
+// DoWith is Do with a specific client, such as one with custom TLS settings
+func DoWith(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			fmt.Printf("failed to close response body %s", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return body, &HTTPError{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}
+	}
+
+	return body, nil
+}
@@ -0,0 +1,347 @@
+package jira
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+const (
+	DefaultIssueType = "Task"
+	DefaultSummary   = "{{ .Reason }}: {{ .InvolvedObject.Kind }} {{ .InvolvedObject.Namespace }}/{{ .InvolvedObject.Name }}"
+
+	// fingerprintPrefix and objectPrefix label issues with the fingerprint of their
+	// condition and with the object they are about, which resolving looks up
+	fingerprintPrefix = "k8s-event-"
+	objectPrefix      = "k8s-object-"
+	digestSummary     = "Kubernetes event digest"
+)
+
+// Issue field limits of Jira
+const (
+	maxSummary     = 255
+	maxDescription = 32767
+)
+
+// Config defines the Jira instance and the issues created in it
+type Config struct {
+	// URL of the instance, such as https://example.atlassian.net
+	URL string
+	// Username is the account email on Jira Cloud. Without it the token is sent
+	// as a personal access token, as used by Jira Data Center.
+	Username string
+	Token    string
+	// Project key issues are created in
+	Project   string
+	IssueType string
+	// Summary and Description are templates over the event, the description
+	// defaults to the notification message
+	Summary     string
+	Description string
+	Labels      []string
+	// Fields are templates of further fields by ID, such as customfield_10010.
+	// Values rendering to a JSON object or array are sent as is, others as strings.
+	Fields map[string]string
+	// ResolveTransition is the transition applied to open issues of an object
+	// once it recovers, such as Done. Issues are left open if empty.
+	ResolveTransition string
+}
+
+// JiraPublisher opens an issue per recurring condition. Events sharing the
+// fingerprint of an open issue are added to it as comments.
+type JiraPublisher struct {
+	config      Config
+	summary     *template.Template
+	description *template.Template
+	fields      map[string]*template.Template
+	searches    *SearchCache
+}
+
+// SearchCache remembers the instances that turned out not to know the /search/jql
+// endpoint, by URL, so that a publisher created on every reconcile does not try it
+// again each time. The zero value is ready to use.
+type SearchCache struct {
+	mu     sync.Mutex
+	legacy map[string]bool
+}
+
+func (c *SearchCache) isLegacy(url string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.legacy[url]
+}
+
+func (c *SearchCache) setLegacy(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.legacy == nil {
+		c.legacy = make(map[string]bool)
+	}
+	c.legacy[url] = true
+}
+
+type issue struct {
+	Key string `json:"key"`
+}
+
+type searchResponse struct {
+	Issues []issue `json:"issues"`
+}
+
+type transitionsResponse struct {
+	Transitions []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		To   struct {
+			Name string `json:"name"`
+		} `json:"to"`
+	} `json:"transitions"`
+}
+
+// NewJiraPublisher creates a publisher, taking the search endpoint of the instance
+// from searches. A nil cache keeps it for the lifetime of the publisher only.
+func NewJiraPublisher(config Config, searches *SearchCache) (*JiraPublisher, error) {
+	if config.IssueType == "" {
+		config.IssueType = DefaultIssueType
+	}
+	if config.Summary == "" {
+		config.Summary = DefaultSummary
+	}
+
+	config.URL = strings.TrimSuffix(config.URL, "/")
+	if searches == nil {
+		searches = &SearchCache{}
+	}
+
+	j := &JiraPublisher{config: config, fields: map[string]*template.Template{}, searches: searches}
+	var err error
+	if j.summary, err = parse("summary", config.Summary); err != nil {
+		return nil, err
+	}
+	if config.Description != "" {
+		if j.description, err = parse("description", config.Description); err != nil {
+			return nil, err
+		}
+	}
+	for id, text := range config.Fields {
+		if j.fields[id], err = parse(id, text); err != nil {
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+func parse(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return t, nil
+}
+
+func (j *JiraPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	if notification.Event == nil {
+		return j.createDigest(ctx, notification)
+	}
+
+	fingerprint := fingerprintPrefix + publisher.Fingerprint(notification)
+	issues, err := j.openIssues(ctx, fingerprint, 1)
+	if err != nil {
+		return err
+	}
+	if len(issues) > 0 {
+		return j.comment(ctx, issues[0].Key, fmt.Sprintf("Occurred again (count %d): %s", notification.Event.Count, notification.Message))
+	}
+
+	fields, err := j.buildFields(notification)
+	if err != nil {
+		return err
+	}
+	fields["labels"] = append(append([]string{}, j.config.Labels...), fingerprint, objectPrefix+publisher.OwnerFingerprint(notification))
+	return j.create(ctx, fields)
+}
+
+// Resolve comments on the open issues of the notification object and applies the resolve transition
+func (j *JiraPublisher) Resolve(ctx context.Context, notification publisher.Notification) error {
+	if notification.Event == nil || j.config.ResolveTransition == "" {
+		return nil
+	}
+
+	issues, err := j.openIssues(ctx, objectPrefix+publisher.OwnerFingerprint(notification), 50)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, issue := range issues {
+		note := fmt.Sprintf("Resolved by %s event: %s", notification.Event.Reason, notification.Event.Message)
+		if err := j.comment(ctx, issue.Key, note); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := j.transition(ctx, issue.Key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (j *JiraPublisher) buildFields(notification publisher.Notification) (map[string]any, error) {
+	data := publisher.NewTemplateData(notification)
+	summary, err := render(j.summary, data)
+	if err != nil {
+		return nil, err
+	}
+	description := notification.Message
+	if j.description != nil {
+		if description, err = render(j.description, data); err != nil {
+			return nil, err
+		}
+	}
+
+	fields := map[string]any{
+		"project":     map[string]string{"key": j.config.Project},
+		"issuetype":   map[string]string{"name": j.config.IssueType},
+		"summary":     publisher.Truncate(strings.TrimSpace(summary), maxSummary),
+		"description": publisher.Truncate(description, maxDescription),
+	}
+	for id, t := range j.fields {
+		value, err := render(t, data)
+		if err != nil {
+			return nil, err
+		}
+		if trimmed := strings.TrimSpace(value); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			if json.Valid([]byte(trimmed)) {
+				fields[id] = json.RawMessage(trimmed)
+				continue
+			}
+		}
+		fields[id] = value
+	}
+	return fields, nil
+}
+
+func render(t *template.Template, data publisher.TemplateData) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", t.Name(), err)
+	}
+	return b.String(), nil
+}
+
+func (j *JiraPublisher) createDigest(ctx context.Context, notification publisher.Notification) error {
+	return j.create(ctx, map[string]any{
+		"project":     map[string]string{"key": j.config.Project},
+		"issuetype":   map[string]string{"name": j.config.IssueType},
+		"summary":     digestSummary,
+		"description": publisher.Truncate(notification.Message, maxDescription),
+		"labels":      append([]string{}, j.config.Labels...),
+	})
+}
+
+func (j *JiraPublisher) create(ctx context.Context, fields map[string]any) error {
+	if _, err := j.post(ctx, "/rest/api/2/issue", map[string]any{"fields": fields}); err != nil {
+		return fmt.Errorf("failed to create jira issue: %w", err)
+	}
+	return nil
+}
+
+func (j *JiraPublisher) comment(ctx context.Context, key, body string) error {
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/comment"
+	if _, err := j.post(ctx, path, map[string]string{"body": body}); err != nil {
+		return fmt.Errorf("failed to comment on jira issue %s: %w", key, err)
+	}
+	return nil
+}
+
+func (j *JiraPublisher) transition(ctx context.Context, key string) error {
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/transitions"
+	body, err := j.get(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to list transitions of jira issue %s: %w", key, err)
+	}
+	var response transitionsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("invalid transitions of jira issue %s: %w", key, err)
+	}
+
+	for _, t := range response.Transitions {
+		if strings.EqualFold(t.Name, j.config.ResolveTransition) || strings.EqualFold(t.To.Name, j.config.ResolveTransition) {
+			request := map[string]any{"transition": map[string]string{"id": t.ID}}
+			if _, err := j.post(ctx, path, request); err != nil {
+				return fmt.Errorf("failed to transition jira issue %s: %w", key, err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("jira issue %s has no transition %q", key, j.config.ResolveTransition)
+}
+
+// openIssues searches the unresolved issues of the project carrying label
+func (j *JiraPublisher) openIssues(ctx context.Context, label string, limit int) ([]issue, error) {
+	query := url.Values{
+		"jql":        {fmt.Sprintf("project = %q AND labels = %q AND statusCategory != Done ORDER BY created DESC", j.config.Project, label)},
+		"fields":     {"key"},
+		"maxResults": {fmt.Sprint(limit)},
+	}
+
+	// Jira Cloud replaced /search by /search/jql, Data Center only knows the former
+	var body []byte
+	var err error
+	legacy := j.searches.isLegacy(j.config.URL)
+	if !legacy {
+		body, err = j.get(ctx, "/rest/api/2/search/jql?"+query.Encode())
+		var httpErr *publisher.HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+			j.searches.setLegacy(j.config.URL)
+			legacy = true
+		}
+	}
+	if legacy {
+		body, err = j.get(ctx, "/rest/api/2/search?"+query.Encode())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search jira issues: %w", err)
+	}
+
+	var response searchResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid jira search response: %w", err)
+	}
+	return response.Issues, nil
+}
+
+func (j *JiraPublisher) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.endpoint(path), nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range j.header() {
+		req.Header[key] = values
+	}
+	return publisher.Do(req)
+}
+
+func (j *JiraPublisher) post(ctx context.Context, path string, payload any) ([]byte, error) {
+	return publisher.PostJSON(ctx, j.endpoint(path), payload, j.header())
+}
+
+func (j *JiraPublisher) endpoint(path string) string {
+	return j.config.URL + path
+}
+
+func (j *JiraPublisher) header() http.Header {
+	authorization := "Bearer " + j.config.Token
+	if j.config.Username != "" {
+		authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(j.config.Username+":"+j.config.Token))
+	}
+	return http.Header{"Accept": []string{"application/json"}, "Authorization": []string{authorization}}
+}
@@ -0,0 +1,281 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+const (
+	// DefaultPartitionKey keeps the events of one workload in order on one partition
+	DefaultPartitionKey  = "{{ .Namespace }}/{{ .Owner }}"
+	DefaultBatchSize     = 100
+	DefaultFlushInterval = time.Second
+
+	clientID  = "k8s-event-notifier"
+	digestKey = "digest"
+	// connectRetryInterval is the delay between attempts while no broker has been reached yet
+	connectRetryInterval = 30 * time.Second
+)
+
+// SASL mechanisms
+const (
+	SASLPlain       = sarama.SASLTypePlaintext
+	SASLScramSHA256 = sarama.SASLTypeSCRAMSHA256
+	SASLScramSHA512 = sarama.SASLTypeSCRAMSHA512
+)
+
+type SASL struct {
+	Mechanism string
+	Username  string
+	Password  string
+}
+
+// ProducerConfig defines the connection and delivery settings of a producer
+type ProducerConfig struct {
+	Brokers []string
+	TLS     *publisher.TLSConfig
+	SASL    *SASL
+	// Idempotent enables exactly-once delivery per partition, which requires acks from all in-sync replicas
+	Idempotent bool
+	// BatchSize is the number of messages that triggers a flush
+	BatchSize int
+	// FlushInterval is the longest a message is buffered before it is sent
+	FlushInterval time.Duration
+}
+
+// Producer sends messages in batches in the background. It connects in the background
+// too, retrying until a broker is reached, and rejects messages until then. Connection
+// and delivery failures are reported to the error handler as they happen, after the
+// producer retries.
+type Producer struct {
+	mu       sync.RWMutex
+	closed   bool
+	producer sarama.AsyncProducer
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewProducer(config ProducerConfig, onError func(error)) (*Producer, error) {
+	saramaConfig, err := config.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Producer{stop: make(chan struct{}), done: make(chan struct{})}
+	go p.connect(config.Brokers, saramaConfig, onError)
+	return p, nil
+}
+
+func newProducer(producer sarama.AsyncProducer, onError func(error)) *Producer {
+	p := &Producer{producer: producer, stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(p.done)
+		reportErrors(producer, onError)
+	}()
+	return p
+}
+
+// connect creates the producer, which needs a broker to fetch the cluster metadata from
+func (p *Producer) connect(brokers []string, config *sarama.Config, onError func(error)) {
+	defer close(p.done)
+	for {
+		producer, err := sarama.NewAsyncProducer(brokers, config)
+		if err == nil {
+			if !p.start(producer) {
+				_ = producer.Close()
+				return
+			}
+			reportErrors(producer, onError)
+			return
+		}
+		if onError != nil {
+			onError(fmt.Errorf("failed to create kafka producer, retrying in %s: %w", connectRetryInterval, err))
+		}
+
+		select {
+		case <-p.stop:
+			return
+		case <-time.After(connectRetryInterval):
+		}
+	}
+}
+
+// start makes producer the one messages are sent to, false if closed meanwhile
+func (p *Producer) start(producer sarama.AsyncProducer) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return false
+	}
+	p.producer = producer
+	return true
+}
+
+func reportErrors(producer sarama.AsyncProducer, onError func(error)) {
+	for err := range producer.Errors() {
+		if onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// Close flushes buffered messages and closes the connections to the brokers
+func (p *Producer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	close(p.stop)
+	if p.producer == nil {
+		// still connecting, which stops at the next attempt
+		return nil
+	}
+	err := p.producer.Close()
+	<-p.done
+	return err
+}
+
+func (p *Producer) send(ctx context.Context, message *sarama.ProducerMessage) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return errors.New("kafka producer is closed")
+	}
+	if p.producer == nil {
+		return errors.New("kafka producer is not connected to a broker yet")
+	}
+	select {
+	case p.producer.Input() <- message:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c ProducerConfig) saramaConfig() (*sarama.Config, error) {
+	config := sarama.NewConfig()
+	config.ClientID = clientID
+	config.Version = sarama.V2_1_0_0
+	config.Producer.Return.Errors = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Partitioner = sarama.NewHashPartitioner
+
+	if c.Idempotent {
+		config.Producer.Idempotent = true
+		// ordering with retries is only guaranteed with a single in-flight request
+		config.Net.MaxOpenRequests = 1
+	}
+
+	config.Producer.Flush.Messages = c.BatchSize
+	if config.Producer.Flush.Messages <= 0 {
+		config.Producer.Flush.Messages = DefaultBatchSize
+	}
+	config.Producer.Flush.Frequency = c.FlushInterval
+	if config.Producer.Flush.Frequency <= 0 {
+		config.Producer.Flush.Frequency = DefaultFlushInterval
+	}
+
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.Build()
+		if err != nil {
+			return nil, err
+		}
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	if c.SASL != nil {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.Handshake = true
+		config.Net.SASL.User = c.SASL.Username
+		config.Net.SASL.Password = c.SASL.Password
+		config.Net.SASL.Mechanism = sarama.SASLMechanism(c.SASL.Mechanism)
+		switch config.Net.SASL.Mechanism {
+		case "", sarama.SASLTypePlaintext:
+			config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		case sarama.SASLTypeSCRAMSHA256, sarama.SASLTypeSCRAMSHA512:
+			config.Net.SASL.SCRAMClientGeneratorFunc = newSCRAMClient(config.Net.SASL.Mechanism)
+		default:
+			return nil, fmt.Errorf("unsupported SASL mechanism %s", c.SASL.Mechanism)
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid kafka producer settings: %w", err)
+	}
+	return config, nil
+}
+
+// KafkaPublisher writes notifications to a topic, keyed by the partition key template
+type KafkaPublisher struct {
+	Producer *Producer
+	Topic    string
+	key      *template.Template
+}
+
+func NewKafkaPublisher(producer *Producer, topic, partitionKey string) (*KafkaPublisher, error) {
+	if partitionKey == "" {
+		partitionKey = DefaultPartitionKey
+	}
+	key, err := template.New("key").Option("missingkey=zero").Parse(partitionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid partition key template: %w", err)
+	}
+	return &KafkaPublisher{Producer: producer, Topic: topic, key: key}, nil
+}
+
+func (k *KafkaPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	message, err := k.buildMessage(notification)
+	if err != nil {
+		return err
+	}
+	if err := k.Producer.send(ctx, message); err != nil {
+		return fmt.Errorf("failed to queue kafka message: %w", err)
+	}
+	return nil
+}
+
+func (k *KafkaPublisher) buildMessage(notification publisher.Notification) (*sarama.ProducerMessage, error) {
+	record := publisher.NewRecord(notification)
+	key := digestKey
+	var headers []sarama.RecordHeader
+
+	if event := notification.Event; event != nil {
+		var b strings.Builder
+		if err := k.key.Execute(&b, publisher.NewTemplateData(notification)); err != nil {
+			return nil, fmt.Errorf("failed to render partition key: %w", err)
+		}
+		key = b.String()
+		headers = []sarama.RecordHeader{
+			{Key: []byte("type"), Value: []byte(event.Type)},
+			{Key: []byte("reason"), Value: []byte(event.Reason)},
+		}
+	}
+
+	value, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sarama.ProducerMessage{
+		Topic:   k.Topic,
+		Key:     sarama.StringEncoder(key),
+		Value:   sarama.ByteEncoder(value),
+		Headers: headers,
+	}, nil
+}
@@ -0,0 +1,165 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func backOffEvent() *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop", UID: "e1"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "cart-7f9c-x2"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Type:           corev1.EventTypeWarning,
+	}
+}
+
+func mockConfig() *sarama.Config {
+	config := sarama.NewConfig()
+	config.Producer.Return.Errors = true
+	return config
+}
+
+func TestKafkaPublisherSend(t *testing.T) {
+	mock := mocks.NewAsyncProducer(t, mockConfig())
+	mock.ExpectInputWithMessageCheckerFunctionAndSucceed(func(message *sarama.ProducerMessage) error {
+		key, _ := message.Key.Encode()
+		if string(key) != "shop/Deployment/cart" {
+			t.Errorf("unexpected key %q", key)
+		}
+		if message.Topic != "k8s-events" || len(message.Headers) != 2 || string(message.Headers[1].Value) != "BackOff" {
+			t.Errorf("unexpected message %+v", message)
+		}
+
+		value, _ := message.Value.Encode()
+		var record publisher.Record
+		if err := json.Unmarshal(value, &record); err != nil {
+			return err
+		}
+		if record.Cluster != "prod" || record.Owner != "Deployment/cart" || record.Event == nil || record.Event.UID != "e1" {
+			t.Errorf("unexpected record %s", value)
+		}
+		return nil
+	})
+
+	producer := newProducer(mock, func(err error) { t.Errorf("unexpected delivery error: %v", err) })
+	p, err := NewKafkaPublisher(producer, "k8s-events", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notification := publisher.Notification{Event: backOffEvent(), ClusterName: "prod", Owner: "Deployment/cart"}
+	if err := p.Send(context.Background(), notification); err != nil {
+		t.Fatal(err)
+	}
+	if err := producer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Send(context.Background(), notification); err == nil {
+		t.Error("expected an error sending on a closed producer")
+	}
+}
+
+func TestKafkaPublisherKeyTemplateAndErrors(t *testing.T) {
+	mock := mocks.NewAsyncProducer(t, mockConfig())
+	mock.ExpectInputWithMessageCheckerFunctionAndFail(func(message *sarama.ProducerMessage) error {
+		key, _ := message.Key.Encode()
+		if string(key) != "prod:BackOff" {
+			t.Errorf("unexpected key %q", key)
+		}
+		return nil
+	}, sarama.ErrNotEnoughReplicas)
+
+	errs := make(chan error, 1)
+	producer := newProducer(mock, func(err error) { errs <- err })
+	defer func() { _ = producer.Close() }()
+
+	p, err := NewKafkaPublisher(producer, "k8s-events", "{{ .ClusterName }}:{{ .Reason }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(context.Background(), publisher.Notification{Event: backOffEvent(), ClusterName: "prod"}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, sarama.ErrNotEnoughReplicas) {
+			t.Errorf("unexpected delivery error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the delivery error to be reported")
+	}
+}
+
+func TestProducerUnreachable(t *testing.T) {
+	errs := make(chan error, 10)
+	producer, err := NewProducer(ProducerConfig{Brokers: []string{"127.0.0.1:1"}}, func(err error) { errs <- err })
+	if err != nil {
+		t.Fatalf("expected the producer to connect in the background, got %v", err)
+	}
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, sarama.ErrOutOfBrokers) {
+			t.Errorf("unexpected connection error %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the connection error to be reported")
+	}
+
+	p, err := NewKafkaPublisher(producer, "k8s-events", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(context.Background(), publisher.Notification{Event: backOffEvent()}); err == nil {
+		t.Error("expected an error sending before a broker was reached")
+	}
+	if err := producer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKafkaPublisherInvalidTemplate(t *testing.T) {
+	if _, err := NewKafkaPublisher(nil, "topic", "{{ .Namespace"); err == nil {
+		t.Error("expected an error for an invalid key template")
+	}
+}
+
+func TestProducerConfig(t *testing.T) {
+	config, err := ProducerConfig{
+		Brokers:    []string{"kafka-0:9093"},
+		Idempotent: true,
+		SASL:       &SASL{Mechanism: SASLScramSHA512, Username: "notifier", Password: "secret"},
+		TLS:        &publisher.TLSConfig{},
+	}.saramaConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !config.Producer.Idempotent || config.Net.MaxOpenRequests != 1 || config.Producer.RequiredAcks != sarama.WaitForAll {
+		t.Errorf("expected idempotent producer settings, got %+v", config.Producer)
+	}
+	if config.Producer.Flush.Messages != DefaultBatchSize || config.Producer.Flush.Frequency != DefaultFlushInterval {
+		t.Errorf("expected default batching, got %+v", config.Producer.Flush)
+	}
+	if !config.Net.TLS.Enable || !config.Net.SASL.Enable || config.Net.SASL.SCRAMClientGeneratorFunc == nil {
+		t.Errorf("expected TLS and SCRAM authentication, got %+v", config.Net)
+	}
+
+	if _, err := (ProducerConfig{SASL: &SASL{Mechanism: "GSSAPI"}}).saramaConfig(); err == nil {
+		t.Error("expected an error for an unsupported SASL mechanism")
+	}
+}
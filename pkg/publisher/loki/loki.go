@@ -0,0 +1,305 @@
+package loki
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/example/notifier/pkg/publisher"
+	"github.com/example/notifier/pkg/publisher/batch"
+)
+
+// Encodings of the push request
+const (
+	EncodingProtobuf = "protobuf"
+	EncodingJSON     = "json"
+)
+
+// Labels streams can be selected by, each with a bounded number of values
+const (
+	LabelNamespace = "namespace"
+	LabelReason    = "reason"
+	LabelKind      = "kind"
+	LabelCluster   = "cluster"
+)
+
+// DefaultLabels are all the labels streams can be selected by
+var DefaultLabels = []string{LabelNamespace, LabelReason, LabelKind, LabelCluster}
+
+const (
+	pushPath = "/loki/api/v1/push"
+	// digestReason labels the digest stream
+	digestReason = "Digest"
+	// fallbackJob labels the streams none of the selected labels have a value for,
+	// as Loki rejects streams without labels
+	fallbackJob = "k8s-event-notifier"
+)
+
+var now = time.Now
+
+// Config defines the Loki instance and how entries are pushed
+type Config struct {
+	// URL of Loki or its gateway, such as http://loki:3100
+	URL string
+	// TenantID is sent as X-Scope-OrgID to multi-tenant instances
+	TenantID string
+	// Encoding is protobuf, compressed with snappy, or json
+	Encoding string
+	// Labels streams are selected by, DefaultLabels if empty
+	Labels   []string
+	Username string
+	Password string
+	TLS      *publisher.TLSConfig
+	// BatchSize is the number of entries sent per push
+	BatchSize int
+	// FlushInterval is the longest an entry waits for its batch to fill up
+	FlushInterval time.Duration
+}
+
+// entry is a log line of the stream its labels select
+type entry struct {
+	labels    map[string]string
+	timestamp time.Time
+	line      string
+}
+
+// stream groups the entries of a batch with the same labels, oldest first
+type stream struct {
+	labels  map[string]string
+	entries []entry
+}
+
+// LokiPublisher pushes notifications as JSON log lines. Streams are labelled by
+// a subset of cluster, namespace, kind and reason only, so their number stays
+// bounded; object names and owners are in the lines.
+type LokiPublisher struct {
+	config  Config
+	labels  map[string]bool
+	client  *http.Client
+	batcher *batch.Batcher[entry]
+}
+
+func NewLokiPublisher(config Config, onError func(error)) (*LokiPublisher, error) {
+	switch config.Encoding {
+	case "":
+		config.Encoding = EncodingProtobuf
+	case EncodingProtobuf, EncodingJSON:
+	default:
+		return nil, fmt.Errorf("unsupported loki encoding %q", config.Encoding)
+	}
+	if len(config.Labels) == 0 {
+		config.Labels = DefaultLabels
+	}
+	labels := make(map[string]bool, len(config.Labels))
+	for _, label := range config.Labels {
+		switch label {
+		case LabelNamespace, LabelReason, LabelKind, LabelCluster:
+			labels[label] = true
+		default:
+			return nil, fmt.Errorf("unsupported loki label %q", label)
+		}
+	}
+	client, err := config.TLS.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &LokiPublisher{config: config, labels: labels, client: client}
+	l.batcher = batch.New(config.BatchSize, config.FlushInterval, l.flush, onError)
+	return l, nil
+}
+
+func (l *LokiPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	e, err := l.buildEntry(notification)
+	if err != nil {
+		return err
+	}
+	if err := l.batcher.Add(ctx, e); err != nil {
+		return fmt.Errorf("failed to queue loki entry: %w", err)
+	}
+	return nil
+}
+
+// Close pushes the queued entries
+func (l *LokiPublisher) Close() error {
+	return l.batcher.Close()
+}
+
+func (l *LokiPublisher) buildEntry(notification publisher.Notification) (entry, error) {
+	line, err := json.Marshal(publisher.NewRecord(notification))
+	if err != nil {
+		return entry{}, err
+	}
+	e := entry{
+		labels:    map[string]string{LabelCluster: notification.ClusterName, LabelReason: digestReason},
+		timestamp: now(),
+		line:      string(line),
+	}
+
+	if event := notification.Event; event != nil {
+		namespace := event.InvolvedObject.Namespace
+		if namespace == "" {
+			namespace = event.Namespace
+		}
+		e.labels[LabelNamespace] = namespace
+		e.labels[LabelKind] = event.InvolvedObject.Kind
+		e.labels[LabelReason] = event.Reason
+		e.timestamp = publisher.EventTime(event, e.timestamp)
+	}
+
+	// Loki rejects empty label values
+	for name, value := range e.labels {
+		if value == "" || !l.labels[name] {
+			delete(e.labels, name)
+		}
+	}
+	if len(e.labels) == 0 {
+		e.labels["job"] = fallbackJob
+	}
+	return e, nil
+}
+
+func (l *LokiPublisher) flush(ctx context.Context, entries []entry) ([]entry, error) {
+	streams := groupStreams(entries)
+
+	var body []byte
+	contentType := "application/json"
+	if l.config.Encoding == EncodingProtobuf {
+		body = snappy.Encode(nil, encodeProtobuf(streams))
+		contentType = "application/x-protobuf"
+	} else {
+		var err error
+		if body, err = encodeJSON(streams); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(l.config.URL, "/")+pushPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if l.config.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", l.config.TenantID)
+	}
+	if l.config.Username != "" {
+		req.SetBasicAuth(l.config.Username, l.config.Password)
+	}
+
+	if _, err := publisher.DoWith(l.client, req); err != nil {
+		var httpErr *publisher.HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode != http.StatusTooManyRequests && httpErr.StatusCode < 500 {
+			// rejected entries, such as ones older than the ingestion window, are not sent again
+			return nil, fmt.Errorf("loki rejected %d entries: %w", len(entries), err)
+		}
+		return entries, fmt.Errorf("failed to push %d entries to loki: %w", len(entries), err)
+	}
+	return nil, nil
+}
+
+func groupStreams(entries []entry) []stream {
+	byLabels := map[string]*stream{}
+	var keys []string
+	for _, e := range entries {
+		key := labelString(e.labels)
+		s, ok := byLabels[key]
+		if !ok {
+			s = &stream{labels: e.labels}
+			byLabels[key] = s
+			keys = append(keys, key)
+		}
+		s.entries = append(s.entries, e)
+	}
+
+	streams := make([]stream, 0, len(keys))
+	for _, key := range keys {
+		s := byLabels[key]
+		sort.SliceStable(s.entries, func(i, j int) bool {
+			return s.entries[i].timestamp.Before(s.entries[j].timestamp)
+		})
+		streams = append(streams, *s)
+	}
+	return streams
+}
+
+// labelString renders labels as a Prometheus selector, {name="value", ...}
+func labelString(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("{")
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(name + "=" + strconv.Quote(labels[name]))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// encodeJSON renders the streams in the JSON push format
+func encodeJSON(streams []stream) ([]byte, error) {
+	type jsonStream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	request := struct {
+		Streams []jsonStream `json:"streams"`
+	}{}
+	for _, s := range streams {
+		js := jsonStream{Stream: s.labels}
+		for _, e := range s.entries {
+			js.Values = append(js.Values, [2]string{strconv.FormatInt(e.timestamp.UnixNano(), 10), e.line})
+		}
+		request.Streams = append(request.Streams, js)
+	}
+	return json.Marshal(request)
+}
+
+// encodeProtobuf renders the streams as a logproto.PushRequest:
+//
+//	PushRequest { repeated Stream streams = 1; }
+//	Stream { string labels = 1; repeated Entry entries = 2; }
+//	Entry { google.protobuf.Timestamp timestamp = 1; string line = 2; }
+func encodeProtobuf(streams []stream) []byte {
+	var request []byte
+	for _, s := range streams {
+		var message []byte
+		message = protowire.AppendTag(message, 1, protowire.BytesType)
+		message = protowire.AppendString(message, labelString(s.labels))
+		for _, e := range s.entries {
+			var timestamp []byte
+			timestamp = protowire.AppendTag(timestamp, 1, protowire.VarintType)
+			timestamp = protowire.AppendVarint(timestamp, uint64(e.timestamp.Unix()))
+			timestamp = protowire.AppendTag(timestamp, 2, protowire.VarintType)
+			timestamp = protowire.AppendVarint(timestamp, uint64(e.timestamp.Nanosecond()))
+
+			var protoEntry []byte
+			protoEntry = protowire.AppendTag(protoEntry, 1, protowire.BytesType)
+			protoEntry = protowire.AppendBytes(protoEntry, timestamp)
+			protoEntry = protowire.AppendTag(protoEntry, 2, protowire.BytesType)
+			protoEntry = protowire.AppendString(protoEntry, e.line)
+
+			message = protowire.AppendTag(message, 2, protowire.BytesType)
+			message = protowire.AppendBytes(message, protoEntry)
+		}
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, message)
+	}
+	return request
+}
@@ -0,0 +1,251 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func backOffEvent(name string, minute int) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop", UID: "e1"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: name},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Type:           corev1.EventTypeWarning,
+		LastTimestamp:  metav1.NewTime(time.Date(2024, 5, 1, 12, minute, 0, 0, time.UTC)),
+	}
+}
+
+type pushedStream struct {
+	labels  string
+	entries []pushedEntry
+}
+
+type pushedEntry struct {
+	timestamp time.Time
+	line      string
+}
+
+// decodePush decodes a logproto.PushRequest
+func decodePush(t *testing.T, b []byte) []pushedStream {
+	var streams []pushedStream
+	for _, stream := range fields(t, b)[1] {
+		var s pushedStream
+		streamFields := fields(t, stream)
+		s.labels = string(streamFields[1][0])
+		for _, e := range streamFields[2] {
+			entryFields := fields(t, e)
+			var seconds, nanos uint64
+			for number, values := range fields(t, entryFields[1][0]) {
+				v, _ := protowire.ConsumeVarint(values[0])
+				if number == 1 {
+					seconds = v
+				} else {
+					nanos = v
+				}
+			}
+			s.entries = append(s.entries, pushedEntry{
+				timestamp: time.Unix(int64(seconds), int64(nanos)).UTC(),
+				line:      string(entryFields[2][0]),
+			})
+		}
+		streams = append(streams, s)
+	}
+	return streams
+}
+
+// fields returns the raw values of a message by field number, varints re-encoded
+func fields(t *testing.T, b []byte) map[protowire.Number][][]byte {
+	values := map[protowire.Number][][]byte{}
+	for len(b) > 0 {
+		number, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatal(protowire.ParseError(n))
+		}
+		b = b[n:]
+		switch typ {
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				t.Fatal(protowire.ParseError(n))
+			}
+			values[number] = append(values[number], v)
+			b = b[n:]
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				t.Fatal(protowire.ParseError(n))
+			}
+			values[number] = append(values[number], protowire.AppendVarint(nil, v))
+			b = b[n:]
+		default:
+			t.Fatalf("unexpected wire type %v", typ)
+		}
+	}
+	return values
+}
+
+func TestLokiPublisherProtobuf(t *testing.T) {
+	requests := make(chan []pushedStream, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != pushPath || r.Header.Get("X-Scope-OrgID") != "team-a" || r.Header.Get("Content-Type") != "application/x-protobuf" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		compressed, _ := io.ReadAll(r.Body)
+		body, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			t.Error(err)
+		}
+		requests <- decodePush(t, body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	p, err := NewLokiPublisher(Config{URL: server.URL + "/", TenantID: "team-a"}, func(err error) {
+		t.Errorf("unexpected error: %v", err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, n := range []publisher.Notification{
+		{Event: backOffEvent("cart-1", 31), ClusterName: "prod"},
+		{Event: backOffEvent("cart-0", 30), ClusterName: "prod", Owner: "StatefulSet/cart"},
+		{Message: "3 events", ClusterName: "prod"},
+	} {
+		if err := p.Send(ctx, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	streams := <-requests
+	if len(streams) != 2 {
+		t.Fatalf("expected an event and a digest stream, got %+v", streams)
+	}
+	events := streams[0]
+	if events.labels != `{cluster="prod", kind="Pod", namespace="shop", reason="BackOff"}` {
+		t.Errorf("unexpected labels %s", events.labels)
+	}
+	if len(events.entries) != 2 || !events.entries[0].timestamp.Equal(time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)) {
+		t.Fatalf("expected entries in timestamp order, got %+v", events.entries)
+	}
+	var record publisher.Record
+	if err := json.Unmarshal([]byte(events.entries[0].line), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Owner != "StatefulSet/cart" || record.Event.InvolvedObject.Name != "cart-0" {
+		t.Errorf("unexpected line %s", events.entries[0].line)
+	}
+	if streams[1].labels != `{cluster="prod", reason="Digest"}` {
+		t.Errorf("unexpected digest labels %s", streams[1].labels)
+	}
+}
+
+func TestLokiPublisherJSON(t *testing.T) {
+	status := http.StatusNoContent
+	var pushed struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		if user != "notifier" || password != "secret" || r.Header.Get("X-Scope-OrgID") != "" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&pushed); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	p, err := NewLokiPublisher(Config{URL: server.URL, Encoding: EncodingJSON, Username: "notifier", Password: "secret"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = p.Close() }()
+
+	e, err := p.buildEntry(publisher.Notification{Event: backOffEvent("cart-0", 30)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.flush(context.Background(), []entry{e}); err != nil {
+		t.Fatal(err)
+	}
+	if len(pushed.Streams) != 1 || pushed.Streams[0].Stream["reason"] != "BackOff" {
+		t.Fatalf("unexpected push %+v", pushed)
+	}
+	if _, ok := pushed.Streams[0].Stream["cluster"]; ok {
+		t.Errorf("expected empty labels to be left out, got %v", pushed.Streams[0].Stream)
+	}
+	if pushed.Streams[0].Values[0][0] != "1714566600000000000" {
+		t.Errorf("unexpected timestamp %s", pushed.Streams[0].Values[0][0])
+	}
+
+	for _, tc := range []struct {
+		status int
+		retry  int
+	}{
+		{http.StatusTooManyRequests, 1},
+		{http.StatusBadGateway, 1},
+		{http.StatusBadRequest, 0},
+	} {
+		status = tc.status
+		retry, err := p.flush(context.Background(), []entry{e})
+		if err == nil || len(retry) != tc.retry {
+			t.Errorf("status %d: expected %d entries to be retried, got %d: %v", tc.status, tc.retry, len(retry), err)
+		}
+	}
+
+	if _, err := NewLokiPublisher(Config{URL: server.URL, Encoding: "xml"}, nil); err == nil {
+		t.Error("expected unsupported encodings to be rejected")
+	}
+}
+
+func TestLokiPublisherLabels(t *testing.T) {
+	p, err := NewLokiPublisher(Config{URL: "http://loki:3100", Labels: []string{LabelCluster, LabelNamespace}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = p.Close() }()
+
+	for _, tc := range []struct {
+		notification publisher.Notification
+		labels       map[string]string
+	}{
+		{publisher.Notification{Event: backOffEvent("cart-0", 30), ClusterName: "prod"}, map[string]string{"cluster": "prod", "namespace": "shop"}},
+		{publisher.Notification{Message: "3 events", ClusterName: "prod"}, map[string]string{"cluster": "prod"}},
+		{publisher.Notification{Message: "3 events"}, map[string]string{"job": fallbackJob}},
+	} {
+		e, err := p.buildEntry(tc.notification)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(e.labels, tc.labels) {
+			t.Errorf("expected labels %v, got %v", tc.labels, e.labels)
+		}
+	}
+
+	if _, err := NewLokiPublisher(Config{URL: "http://loki:3100", Labels: []string{"pod"}}, nil); err == nil {
+		t.Error("expected an error for an unbounded label")
+	}
+}
@@ -0,0 +1,129 @@
+package mattermost
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+	"github.com/example/notifier/pkg/publisher/slack"
+)
+
+// Post limits, see https://developers.mattermost.com/integrate/reference/message-attachments/
+const (
+	maxText           = 16383
+	maxAttachmentText = 7000
+	maxTitle          = 256
+	maxFieldValue     = 1024
+)
+
+const (
+	colorWarning = "#E67E22"
+	colorNormal  = "#2ECC71"
+	colorOther   = "#95A5A6"
+)
+
+// Payload is a Mattermost incoming webhook request,
+// see https://developers.mattermost.com/integrate/webhooks/incoming/
+type Payload struct {
+	Text        string       `json:"text,omitempty"`
+	Channel     string       `json:"channel,omitempty"`
+	Username    string       `json:"username,omitempty"`
+	IconURL     string       `json:"icon_url,omitempty"`
+	IconEmoji   string       `json:"icon_emoji,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+type Attachment struct {
+	Fallback string  `json:"fallback"`
+	Color    string  `json:"color,omitempty"`
+	Title    string  `json:"title,omitempty"`
+	Text     string  `json:"text,omitempty"`
+	Fields   []Field `json:"fields,omitempty"`
+	Footer   string  `json:"footer,omitempty"`
+}
+
+type Field struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// Overrides replace the defaults configured on the incoming webhook
+type Overrides struct {
+	Channel   string
+	Username  string
+	IconURL   string
+	IconEmoji string
+}
+
+type MattermostPublisher struct {
+	WebhookURL string
+	Overrides
+}
+
+func NewMattermostPublisher(webhookURL string, overrides Overrides) *MattermostPublisher {
+	return &MattermostPublisher{WebhookURL: webhookURL, Overrides: overrides}
+}
+
+func (m *MattermostPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	payload := buildPayload(notification)
+	payload.Channel = m.Channel
+	payload.Username = m.Username
+	payload.IconURL = m.IconURL
+	payload.IconEmoji = m.IconEmoji
+
+	if err := slack.PostWebhook(ctx, m.WebhookURL, payload); err != nil {
+		return fmt.Errorf("mattermost: %w", err)
+	}
+	return nil
+}
+
+func buildPayload(notification publisher.Notification) Payload {
+	event := notification.Event
+	if event == nil {
+		return Payload{Text: publisher.Truncate(notification.Message, maxText)}
+	}
+
+	title := fmt.Sprintf("%s: %s %s", event.Reason, event.InvolvedObject.Kind, event.InvolvedObject.Name)
+	attachment := Attachment{
+		Fallback: publisher.Truncate(title+" - "+event.Message, maxTitle),
+		Color:    color(event.Type),
+		Title:    publisher.Truncate(title, maxTitle),
+		Text:     publisher.Truncate(event.Message, maxAttachmentText),
+		Fields: []Field{
+			field("Namespace", event.Namespace),
+			field("Kind", event.InvolvedObject.Kind),
+			field("Name", event.InvolvedObject.Name),
+			field("Type", event.Type),
+		},
+	}
+	if event.Count > 1 {
+		attachment.Fields = append(attachment.Fields, field("Count", strconv.Itoa(int(event.Count))))
+	}
+	if notification.ClusterName != "" {
+		attachment.Footer = "Cluster " + notification.ClusterName
+	}
+
+	return Payload{Attachments: []Attachment{attachment}}
+}
+
+func field(title, value string) Field {
+	if value == "" {
+		value = "-"
+	}
+	return Field{Title: title, Value: publisher.Truncate(value, maxFieldValue), Short: true}
+}
+
+func color(eventType string) string {
+	switch eventType {
+	case corev1.EventTypeWarning:
+		return colorWarning
+	case corev1.EventTypeNormal:
+		return colorNormal
+	default:
+		return colorOther
+	}
+}
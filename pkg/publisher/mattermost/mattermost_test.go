@@ -0,0 +1,91 @@
+package mattermost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func warningEvent() *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web-0"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Type:           corev1.EventTypeWarning,
+	}
+}
+
+func TestMattermostPublisherSend(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	p := NewMattermostPublisher(server.URL, Overrides{
+		Channel:   "k8s-alerts",
+		Username:  "notifier",
+		IconURL:   "https://example.com/icon.png",
+		IconEmoji: ":warning:",
+	})
+	if err := p.Send(context.Background(), publisher.Notification{Event: warningEvent(), ClusterName: "edge"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if payload["channel"] != "k8s-alerts" || payload["username"] != "notifier" ||
+		payload["icon_url"] != "https://example.com/icon.png" || payload["icon_emoji"] != ":warning:" {
+		t.Errorf("expected overrides in payload, got %+v", payload)
+	}
+
+	attachments, _ := payload["attachments"].([]any)
+	if len(attachments) != 1 {
+		t.Fatalf("expected one attachment, got %+v", payload["attachments"])
+	}
+	attachment := attachments[0].(map[string]any)
+	if attachment["title"] != "BackOff: Pod web-0" || attachment["color"] != colorWarning ||
+		attachment["footer"] != "Cluster edge" || attachment["fallback"] == "" {
+		t.Errorf("unexpected attachment %+v", attachment)
+	}
+}
+
+func TestMattermostPublisherDigestWithoutOverrides(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	if err := NewMattermostPublisher(server.URL, Overrides{}).Send(context.Background(), publisher.Notification{Message: "*Digest*"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload) != 1 || payload["text"] != "*Digest*" {
+		t.Errorf("expected a plain text payload, got %+v", payload)
+	}
+}
+
+func TestMattermostPublisherError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"id": "web.incoming_webhook.channel.app_error"}`))
+	}))
+	defer server.Close()
+
+	err := NewMattermostPublisher(server.URL, Overrides{Channel: "missing"}).Send(context.Background(), publisher.Notification{Event: warningEvent()})
+	if err == nil || !strings.Contains(err.Error(), "app_error") {
+		t.Errorf("expected the response body in the error, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+const (
+	DefaultTopic       = "k8s/events/{{ .Namespace }}/{{ .Reason }}"
+	DefaultDigestTopic = "k8s/events/digest"
+
+	connectTimeout = 10 * time.Second
+	// connectRetryInterval is the delay between attempts while the broker has not been reached yet
+	connectRetryInterval = 30 * time.Second
+	// publishTimeout bounds the wait for the broker to acknowledge a message
+	publishTimeout = 10 * time.Second
+	// quiesce is how long in-flight messages get to complete on disconnect, in milliseconds
+	quiesce = 1000
+)
+
+// ClientConfig defines the broker connection and session
+type ClientConfig struct {
+	// Broker URL such as tcp://host:1883, ssl://host:8883 or wss://host/mqtt
+	Broker   string
+	ClientID string
+	Username string
+	Password string
+	TLS      *publisher.TLSConfig
+	// PersistentSession keeps the session on the broker across reconnects,
+	// so QoS 1 and 2 messages in flight are completed after a reconnect
+	PersistentSession bool
+}
+
+// Client is a connection that reconnects on its own, shared by the publishers with the same settings
+type Client struct {
+	client mqtt.Client
+}
+
+// Connect starts connecting in the background, retrying until the broker is reached.
+// Messages published meanwhile are sent once connected, unless the session is clean.
+// Connection losses and a broker not reached within the connect timeout are
+// reported to the error handler.
+func Connect(config ClientConfig, onError func(error)) (*Client, error) {
+	options := mqtt.NewClientOptions().
+		AddBroker(config.Broker).
+		SetClientID(config.ClientID).
+		SetUsername(config.Username).
+		SetPassword(config.Password).
+		SetCleanSession(!config.PersistentSession).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(connectRetryInterval).
+		SetConnectTimeout(connectTimeout).
+		SetOrderMatters(false).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			if onError != nil {
+				onError(err)
+			}
+		})
+
+	if config.TLS != nil {
+		tlsConfig, err := config.TLS.Build()
+		if err != nil {
+			return nil, err
+		}
+		options.SetTLSConfig(tlsConfig)
+	}
+
+	client := mqtt.NewClient(options)
+	// with connect retry the token only completes once connected or disconnected
+	token := client.Connect()
+	go func() {
+		if !token.WaitTimeout(connectTimeout) && onError != nil {
+			onError(fmt.Errorf("not connected to mqtt broker %s yet, retrying every %s", config.Broker, connectRetryInterval))
+		}
+	}()
+	return &Client{client: client}, nil
+}
+
+// Close waits for in-flight messages before disconnecting
+func (c *Client) Close() error {
+	c.client.Disconnect(quiesce)
+	return nil
+}
+
+// Config defines where and how notifications are published
+type Config struct {
+	// Topic is a template over the event, with the owner and cluster added
+	Topic       string
+	DigestTopic string
+	// QoS is the delivery guarantee: 0 at most once, 1 at least once, 2 exactly once
+	QoS byte
+	// Retained keeps the last message of every topic on the broker for new subscribers
+	Retained bool
+}
+
+// MQTTPublisher publishes notifications as JSON records
+type MQTTPublisher struct {
+	Client *Client
+	Config Config
+	topic  *template.Template
+}
+
+func NewMQTTPublisher(client *Client, config Config) (*MQTTPublisher, error) {
+	if config.Topic == "" {
+		config.Topic = DefaultTopic
+	}
+	if config.DigestTopic == "" {
+		config.DigestTopic = DefaultDigestTopic
+	}
+	if config.QoS > 2 {
+		return nil, fmt.Errorf("invalid QoS %d", config.QoS)
+	}
+	topic, err := template.New("topic").Option("missingkey=zero").Parse(config.Topic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid topic template: %w", err)
+	}
+	return &MQTTPublisher{Client: client, Config: config, topic: topic}, nil
+}
+
+func (m *MQTTPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	topic := m.Config.DigestTopic
+	if notification.Event != nil {
+		var b strings.Builder
+		if err := m.topic.Execute(&b, publisher.NewTemplateData(notification)); err != nil {
+			return fmt.Errorf("failed to render topic: %w", err)
+		}
+		topic = b.String()
+	}
+	if topic == "" || strings.ContainsAny(topic, "+#\x00") {
+		return fmt.Errorf("invalid topic %q", topic)
+	}
+
+	payload, err := json.Marshal(publisher.NewRecord(notification))
+	if err != nil {
+		return err
+	}
+
+	// the token completes once the message is written for QoS 0,
+	// acknowledged for QoS 1 and completed for QoS 2
+	token := m.Client.client.Publish(topic, m.Config.QoS, m.Config.Retained, payload)
+	if m.queued(token) {
+		return nil
+	}
+
+	timer := time.NewTimer(publishTimeout)
+	defer timer.Stop()
+	select {
+	case <-token.Done():
+	case <-timer.C:
+		if m.queued(token) {
+			return nil
+		}
+		return fmt.Errorf("timed out publishing to %s", topic)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if err := token.Error(); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", topic, err)
+	}
+	return nil
+}
+
+// queued reports whether the message of a pending token waits for the client to
+// reconnect. Paho keeps it in its store and sends it once connected, so it is accepted.
+func (m *MQTTPublisher) queued(token mqtt.Token) bool {
+	select {
+	case <-token.Done():
+		return false
+	default:
+		return !m.Client.client.IsConnectionOpen()
+	}
+}
@@ -0,0 +1,251 @@
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	server "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func backOffEvent() *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop", UID: "e1"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "cart-7f9c-x2"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Type:           corev1.EventTypeWarning,
+	}
+}
+
+// runBroker starts a broker accepting the notifier user, returning its address
+func runBroker(t *testing.T, tlsConfig *tls.Config) (*server.Server, string) {
+	t.Helper()
+	broker, address, _ := runBrokerAt(t, "127.0.0.1:0", tlsConfig)
+	return broker, address
+}
+
+// runBrokerAt starts a broker listening on address, also returning a function stopping it
+func runBrokerAt(t *testing.T, address string, tlsConfig *tls.Config) (*server.Server, string, func() error) {
+	t.Helper()
+	broker := server.New(&server.Options{InlineClient: true})
+	err := broker.AddHook(new(auth.Hook), &auth.Options{Ledger: &auth.Ledger{
+		Auth: auth.AuthRules{{Username: "notifier", Password: "secret", Allow: true}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := listeners.NewTCP(listeners.Config{ID: "tcp", Address: address, TLSConfig: tlsConfig})
+	if err := broker.AddListener(listener); err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = broker.Serve() }()
+	stop := sync.OnceValue(broker.Close)
+	t.Cleanup(func() { _ = stop() })
+	return broker, listener.Address(), stop
+}
+
+func subscribe(t *testing.T, broker *server.Server, filter string, id int) <-chan packets.Packet {
+	t.Helper()
+	received := make(chan packets.Packet, 10)
+	err := broker.Subscribe(filter, id, func(_ *server.Client, _ packets.Subscription, pk packets.Packet) {
+		received <- pk
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return received
+}
+
+func receive(t *testing.T, received <-chan packets.Packet) packets.Packet {
+	t.Helper()
+	select {
+	case pk := <-received:
+		return pk
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a message")
+		return packets.Packet{}
+	}
+}
+
+// connected waits for the client to reach the broker
+func connected(client *Client, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for !client.client.IsConnectionOpen() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+func TestMQTTPublisherSend(t *testing.T) {
+	broker, address := runBroker(t, nil)
+	received := subscribe(t, broker, "k8s/#", 1)
+
+	client, err := Connect(ClientConfig{
+		Broker:            "tcp://" + address,
+		ClientID:          "notifier-test",
+		Username:          "notifier",
+		Password:          "secret",
+		PersistentSession: true,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = client.Close() }()
+	if !connected(client, 5*time.Second) {
+		t.Fatal("expected the client to connect")
+	}
+
+	if session, ok := broker.Clients.Get("notifier-test"); !ok || session.Properties.Clean {
+		t.Error("expected a persistent session")
+	}
+
+	p, err := NewMQTTPublisher(client, Config{QoS: 1, Retained: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	notification := publisher.Notification{Event: backOffEvent(), ClusterName: "prod", Owner: "Deployment/cart"}
+	if err := p.Send(context.Background(), notification); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(context.Background(), publisher.Notification{Message: "3 events"}); err != nil {
+		t.Fatal(err)
+	}
+
+	pk := receive(t, received)
+	if pk.TopicName != "k8s/events/shop/BackOff" {
+		t.Errorf("unexpected topic %s", pk.TopicName)
+	}
+	var record publisher.Record
+	if err := json.Unmarshal(pk.Payload, &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Owner != "Deployment/cart" || record.Event == nil || record.Event.UID != "e1" {
+		t.Errorf("unexpected record %s", pk.Payload)
+	}
+	if digest := receive(t, received); digest.TopicName != DefaultDigestTopic {
+		t.Errorf("unexpected digest topic %s", digest.TopicName)
+	}
+
+	// new subscribers get the retained message
+	retained := subscribe(t, broker, "k8s/events/shop/+", 2)
+	if pk := receive(t, retained); !pk.FixedHeader.Retain || pk.TopicName != "k8s/events/shop/BackOff" {
+		t.Errorf("expected the retained event, got %+v", pk.FixedHeader)
+	}
+}
+
+func TestMQTTPublisherSendDuringOutage(t *testing.T) {
+	_, address, stop := runBrokerAt(t, "127.0.0.1:0", nil)
+	client, err := Connect(ClientConfig{
+		Broker:            "tcp://" + address,
+		ClientID:          "notifier-outage",
+		Username:          "notifier",
+		Password:          "secret",
+		PersistentSession: true,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = client.Close() }()
+	if !connected(client, 5*time.Second) {
+		t.Fatal("expected the client to connect")
+	}
+	p, err := NewMQTTPublisher(client, Config{QoS: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = stop()
+	deadline := time.Now().Add(5 * time.Second)
+	for client.client.IsConnectionOpen() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// the reconcile context has no deadline, so the send must not wait for the broker
+	start := time.Now()
+	if err := p.Send(context.Background(), publisher.Notification{Event: backOffEvent()}); err != nil {
+		t.Fatalf("expected the message to be queued for reconnect, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the send to return while disconnected, took %s", elapsed)
+	}
+
+	restarted, _, _ := runBrokerAt(t, address, nil)
+	received := subscribe(t, restarted, "k8s/#", 1)
+	if pk := receive(t, received); pk.TopicName != "k8s/events/shop/BackOff" {
+		t.Errorf("expected the queued event after reconnecting, got %s", pk.TopicName)
+	}
+}
+
+func TestConnectTLS(t *testing.T) {
+	// borrow the self-signed certificate httptest issues for 127.0.0.1
+	certServer := httptest.NewUnstartedServer(nil)
+	certServer.StartTLS()
+	defer certServer.Close()
+
+	_, address := runBroker(t, &tls.Config{Certificates: certServer.TLS.Certificates})
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certServer.Certificate().Raw})
+
+	client, err := Connect(ClientConfig{
+		Broker:   "ssl://" + address,
+		ClientID: "notifier-tls",
+		Username: "notifier",
+		Password: "secret",
+		TLS:      &publisher.TLSConfig{CA: string(ca)},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !connected(client, 5*time.Second) {
+		t.Error("expected the client to connect")
+	}
+	_ = client.Close()
+
+	for name, config := range map[string]ClientConfig{
+		"untrusted certificate": {Broker: "ssl://" + address, ClientID: "notifier-tls", Username: "notifier", Password: "secret"},
+		"missing credentials":   {Broker: "ssl://" + address, ClientID: "notifier-tls", TLS: &publisher.TLSConfig{CA: string(ca)}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client, err := Connect(config, nil)
+			if err != nil {
+				t.Fatalf("expected the connection to be retried in the background, got %v", err)
+			}
+			defer func() { _ = client.Close() }()
+			if connected(client, time.Second) {
+				t.Error("expected the client not to connect")
+			}
+		})
+	}
+}
+
+func TestNewMQTTPublisherInvalid(t *testing.T) {
+	if _, err := NewMQTTPublisher(nil, Config{QoS: 3}); err == nil {
+		t.Error("expected an error for an invalid QoS")
+	}
+	if _, err := NewMQTTPublisher(nil, Config{Topic: "{{ .Namespace"}); err == nil {
+		t.Error("expected an error for an invalid topic template")
+	}
+
+	p, err := NewMQTTPublisher(nil, Config{Topic: "k8s/+/{{ .Reason }}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(context.Background(), publisher.Notification{Event: backOffEvent()}); err == nil {
+		t.Error("expected an error for a wildcard topic")
+	}
+}
@@ -0,0 +1,230 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/nats-io/nkeys"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+const (
+	DefaultSubject       = "k8s.events.{{ .Namespace }}.{{ .Reason }}"
+	DefaultDigestSubject = "k8s.events.digest"
+	DefaultAckWait       = 5 * time.Second
+	CloseTimeout         = 10 * time.Second
+
+	clientName = "k8s-event-notifier"
+)
+
+// ConnConfig defines how to connect and authenticate to the servers
+type ConnConfig struct {
+	Servers []string
+	// Credentials is the content of a credentials file, a user JWT and its NKey seed
+	Credentials string
+	// NKeySeed authenticates with a bare NKey instead
+	NKeySeed string
+	TLS      *publisher.TLSConfig
+}
+
+// Conn is a connection that reconnects on its own, shared by the publishers with the same settings
+type Conn struct {
+	nc      *nats.Conn
+	js      jetstream.JetStream
+	closing atomic.Bool
+}
+
+// Connect opens the connection. Servers that cannot be reached are retried in the
+// background, buffering what is published meanwhile. Failed attempts and asynchronous
+// errors, such as slow consumer or permission violations, are reported to the error handler.
+func Connect(config ConnConfig, onError func(error)) (*Conn, error) {
+	conn := &Conn{}
+	options := []nats.Option{
+		nats.Name(clientName),
+		// keep reconnecting for as long as the connection is in use
+		nats.MaxReconnects(-1),
+		nats.RetryOnFailedConnect(true),
+		nats.ReconnectErrHandler(func(_ *nats.Conn, err error) {
+			if onError != nil {
+				onError(fmt.Errorf("failed to connect to nats, retrying: %w", err))
+			}
+		}),
+		// given up on, such as after repeated authorization errors
+		nats.ClosedHandler(func(nc *nats.Conn) {
+			if err := nc.LastError(); err != nil && !conn.closing.Load() && onError != nil {
+				onError(fmt.Errorf("nats connection closed: %w", err))
+			}
+		}),
+		nats.ErrorHandler(func(_ *nats.Conn, _ *nats.Subscription, err error) {
+			if onError != nil {
+				onError(err)
+			}
+		}),
+	}
+
+	switch {
+	case config.Credentials != "":
+		jwt, err := nkeys.ParseDecoratedJWT([]byte(config.Credentials))
+		if err != nil {
+			return nil, fmt.Errorf("invalid nats credentials: %w", err)
+		}
+		keyPair, err := nkeys.ParseDecoratedNKey([]byte(config.Credentials))
+		if err != nil {
+			return nil, fmt.Errorf("invalid nats credentials: %w", err)
+		}
+		options = append(options, nats.UserJWT(
+			func() (string, error) { return jwt, nil },
+			keyPair.Sign,
+		))
+	case config.NKeySeed != "":
+		keyPair, err := nkeys.FromSeed([]byte(strings.TrimSpace(config.NKeySeed)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid nkey seed: %w", err)
+		}
+		publicKey, err := keyPair.PublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid nkey seed: %w", err)
+		}
+		options = append(options, nats.Nkey(publicKey, keyPair.Sign))
+	}
+
+	if config.TLS != nil {
+		tlsConfig, err := config.TLS.Build()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, nats.Secure(tlsConfig))
+	}
+
+	nc, err := nats.Connect(strings.Join(config.Servers, ","), options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	conn.nc, conn.js = nc, js
+	return conn, nil
+}
+
+// Close flushes pending messages, waiting up to CloseTimeout for the servers to
+// receive them, before closing the connection. Messages buffered while reconnecting
+// are dropped rather than waiting for the servers.
+func (c *Conn) Close() error {
+	c.closing.Store(true)
+	var err error
+	if c.nc.IsConnected() {
+		err = c.nc.FlushTimeout(CloseTimeout)
+	}
+	c.nc.Close()
+	if err != nil && !errors.Is(err, nats.ErrConnectionClosed) {
+		return fmt.Errorf("failed to flush nats messages: %w", err)
+	}
+	return nil
+}
+
+// Config defines where and how notifications are published
+type Config struct {
+	// Subject is a template over the event, with the owner and cluster added
+	Subject       string
+	DigestSubject string
+	// JetStream waits for the stream to acknowledge every message, which is
+	// deduplicated by event occurrence
+	JetStream bool
+	AckWait   time.Duration
+}
+
+// NATSPublisher publishes notifications as JSON records
+type NATSPublisher struct {
+	Conn    *Conn
+	Config  Config
+	subject *template.Template
+}
+
+func NewNATSPublisher(conn *Conn, config Config) (*NATSPublisher, error) {
+	if config.Subject == "" {
+		config.Subject = DefaultSubject
+	}
+	if config.DigestSubject == "" {
+		config.DigestSubject = DefaultDigestSubject
+	}
+	if config.AckWait <= 0 {
+		config.AckWait = DefaultAckWait
+	}
+	subject, err := template.New("subject").Option("missingkey=zero").Parse(config.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject template: %w", err)
+	}
+	return &NATSPublisher{Conn: conn, Config: config, subject: subject}, nil
+}
+
+func (n *NATSPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	message, err := n.buildMessage(notification)
+	if err != nil {
+		return err
+	}
+
+	if !n.Config.JetStream {
+		if err := n.Conn.nc.PublishMsg(message); err != nil {
+			return fmt.Errorf("failed to publish to %s: %w", message.Subject, err)
+		}
+		return nil
+	}
+
+	var options []jetstream.PublishOpt
+	if notification.Event != nil {
+		options = append(options, jetstream.WithMsgID(publisher.EventID(notification.Event)))
+	}
+	ctx, cancel := context.WithTimeout(ctx, n.Config.AckWait)
+	defer cancel()
+	if _, err := n.Conn.js.PublishMsg(ctx, message, options...); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", message.Subject, err)
+	}
+	return nil
+}
+
+func (n *NATSPublisher) buildMessage(notification publisher.Notification) (*nats.Msg, error) {
+	message := nats.NewMsg(n.Config.DigestSubject)
+
+	if event := notification.Event; event != nil {
+		var b strings.Builder
+		if err := n.subject.Execute(&b, publisher.NewTemplateData(notification)); err != nil {
+			return nil, fmt.Errorf("failed to render subject: %w", err)
+		}
+		message.Subject = b.String()
+		message.Header.Set("type", event.Type)
+		message.Header.Set("reason", event.Reason)
+	}
+	if err := validSubject(message.Subject); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(publisher.NewRecord(notification))
+	if err != nil {
+		return nil, err
+	}
+	message.Data = data
+	return message, nil
+}
+
+// validSubject rejects subjects a publisher may not use: empty tokens,
+// whitespace and wildcards
+func validSubject(subject string) error {
+	for _, token := range strings.Split(subject, ".") {
+		if token == "" || token == "*" || token == ">" || strings.ContainsAny(token, " \t\r\n") {
+			return fmt.Errorf("invalid subject %q", subject)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/nats-io/nkeys"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func backOffEvent() *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop", UID: "e1"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "cart-7f9c-x2"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Type:           corev1.EventTypeWarning,
+	}
+}
+
+func runServer(t *testing.T, configure func(*server.Options)) *server.Server {
+	t.Helper()
+	options := &server.Options{Host: "127.0.0.1", Port: -1, NoLog: true, NoSigs: true, JetStream: true, StoreDir: t.TempDir()}
+	if configure != nil {
+		configure(options)
+	}
+	s, err := server.NewServer(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Start()
+	if !s.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server did not start")
+	}
+	t.Cleanup(s.Shutdown)
+	return s
+}
+
+func connect(t *testing.T, config ConnConfig) *Conn {
+	t.Helper()
+	conn, err := Connect(config, func(err error) { t.Errorf("unexpected async error: %v", err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestNATSPublisherSend(t *testing.T) {
+	s := runServer(t, nil)
+	conn := connect(t, ConnConfig{Servers: []string{s.ClientURL()}})
+
+	sub, err := conn.nc.SubscribeSync("k8s.events.>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewNATSPublisher(conn, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	notification := publisher.Notification{Event: backOffEvent(), ClusterName: "prod", Owner: "Deployment/cart"}
+	if err := p.Send(context.Background(), notification); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(context.Background(), publisher.Notification{Message: "3 events"}); err != nil {
+		t.Fatal(err)
+	}
+
+	message, err := sub.NextMsg(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Subject != "k8s.events.shop.BackOff" || message.Header.Get("reason") != "BackOff" {
+		t.Errorf("unexpected message %s %v", message.Subject, message.Header)
+	}
+	var record publisher.Record
+	if err := json.Unmarshal(message.Data, &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Cluster != "prod" || record.Owner != "Deployment/cart" || record.Event == nil || record.Event.UID != "e1" {
+		t.Errorf("unexpected record %s", message.Data)
+	}
+
+	digest, err := sub.NextMsg(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest.Subject != DefaultDigestSubject || !json.Valid(digest.Data) {
+		t.Errorf("unexpected digest %s %s", digest.Subject, digest.Data)
+	}
+}
+
+func TestConnCloseFlushes(t *testing.T) {
+	s := runServer(t, nil)
+	conn := connect(t, ConnConfig{Servers: []string{s.ClientURL()}})
+
+	subscriber, err := nats.Connect(s.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer subscriber.Close()
+	sub, err := subscriber.SubscribeSync("k8s.events.>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := subscriber.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewNATSPublisher(conn, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const sent = 100
+	for i := 0; i < sent; i++ {
+		if err := p.Send(context.Background(), publisher.Notification{Event: backOffEvent()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !conn.nc.IsClosed() {
+		t.Error("connection should be closed once Close returns")
+	}
+
+	for i := 0; i < sent; i++ {
+		if _, err := sub.NextMsg(5 * time.Second); err != nil {
+			t.Fatalf("received %d of %d messages: %v", i, sent, err)
+		}
+	}
+}
+
+func TestNATSPublisherJetStreamDedup(t *testing.T) {
+	s := runServer(t, nil)
+	conn := connect(t, ConnConfig{Servers: []string{s.ClientURL()}})
+
+	ctx := context.Background()
+	stream, err := conn.js.CreateStream(ctx, jetstream.StreamConfig{Name: "EVENTS", Subjects: []string{"events.>"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewNATSPublisher(conn, Config{Subject: "events.{{ .ClusterName }}.{{ .Namespace }}", JetStream: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := backOffEvent()
+	notification := publisher.Notification{Event: event, ClusterName: "prod"}
+	// a retry of the same occurrence is dropped, a repetition is not
+	for range 2 {
+		if err := p.Send(ctx, notification); err != nil {
+			t.Fatal(err)
+		}
+	}
+	event.Count = 2
+	if err := p.Send(ctx, notification); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := stream.Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.State.Msgs != 2 {
+		t.Errorf("expected 2 messages in the stream, got %d", info.State.Msgs)
+	}
+
+	// without a stream on the subject there is no acknowledgement
+	unbound, err := NewNATSPublisher(conn, Config{Subject: "unbound.{{ .Namespace }}", JetStream: true, AckWait: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := unbound.Send(ctx, notification); err == nil {
+		t.Error("expected an error without a stream acknowledgement")
+	}
+}
+
+func TestConnectWithNKey(t *testing.T) {
+	user, err := nkeys.CreateUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed, _ := user.Seed()
+	publicKey, _ := user.PublicKey()
+
+	s := runServer(t, func(options *server.Options) {
+		options.Nkeys = []*server.NkeyUser{{Nkey: publicKey}}
+	})
+
+	conn := connect(t, ConnConfig{Servers: []string{s.ClientURL()}, NKeySeed: string(seed) + "\n"})
+	if conn.nc.Status() != nats.CONNECTED {
+		t.Errorf("expected to be connected, got %v", conn.nc.Status())
+	}
+
+	errs := make(chan error, 10)
+	unauthorized, err := Connect(ConnConfig{Servers: []string{s.ClientURL()}}, func(err error) { errs <- err })
+	if err != nil {
+		t.Fatalf("expected the connection to be retried in the background, got %v", err)
+	}
+	defer func() { _ = unauthorized.Close() }()
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "Authorization Violation") {
+			t.Errorf("expected an authorization error without the nkey, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Error("expected the failed connection attempts to be reported")
+	}
+	if _, err := Connect(ConnConfig{Servers: []string{s.ClientURL()}, Credentials: "not a creds file"}, nil); err == nil {
+		t.Error("expected an error for invalid credentials")
+	}
+}
+
+func TestNATSPublisherInvalidSubject(t *testing.T) {
+	if _, err := NewNATSPublisher(nil, Config{Subject: "{{ .Namespace"}); err == nil {
+		t.Error("expected an error for an invalid subject template")
+	}
+
+	p, err := NewNATSPublisher(nil, Config{Subject: "k8s.{{ .InvolvedObject.FieldPath }}.events"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(context.Background(), publisher.Notification{Event: backOffEvent()}); err == nil {
+		t.Error("expected an error for a subject with an empty token")
+	}
+}
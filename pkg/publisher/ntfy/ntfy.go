@@ -0,0 +1,112 @@
+package ntfy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/example/notifier/pkg/publisher"
+	"github.com/example/notifier/pkg/publisher/push"
+)
+
+const (
+	DefaultServerURL = "https://ntfy.sh"
+	DefaultPriority  = 3
+
+	// Priorities range from min to urgent
+	minPriority = 1
+	maxPriority = 5
+	// maxMessage is the largest message ntfy does not turn into an attachment, in bytes
+	maxMessage = 4096
+)
+
+// Config defines the topic notifications are published to
+type Config struct {
+	ServerURL string
+	Topic     string
+	// Token is an access token, notifications are published anonymously if empty
+	Token      string
+	Tags       []string
+	Priorities []publisher.Rule[int]
+	// DefaultPriority applies if no rule matches
+	DefaultPriority int
+	// ClickURL is a template of the page opened when the notification is tapped
+	ClickURL string
+}
+
+type message struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title"`
+	Message  string   `json:"message"`
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags,omitempty"`
+	Click    string   `json:"click,omitempty"`
+}
+
+// NtfyPublisher publishes to a topic of an ntfy server as JSON
+type NtfyPublisher struct {
+	config     Config
+	priorities push.Priorities
+	click      *push.ClickURL
+}
+
+func NewNtfyPublisher(config Config) (*NtfyPublisher, error) {
+	if config.ServerURL == "" {
+		config.ServerURL = DefaultServerURL
+	}
+	config.ServerURL = strings.TrimSuffix(config.ServerURL, "/")
+	if config.DefaultPriority == 0 {
+		config.DefaultPriority = DefaultPriority
+	}
+	if config.Topic == "" {
+		return nil, errors.New("an ntfy topic is required")
+	}
+
+	priorities := push.Priorities{Rules: config.Priorities, Default: config.DefaultPriority}
+	if err := priorities.Validate(minPriority, maxPriority); err != nil {
+		return nil, fmt.Errorf("ntfy: %w", err)
+	}
+	click, err := push.ParseClickURL(config.ClickURL)
+	if err != nil {
+		return nil, err
+	}
+	return &NtfyPublisher{config: config, priorities: priorities, click: click}, nil
+}
+
+func (n *NtfyPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	click, err := n.click.Render(notification)
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	if n.config.Token != "" {
+		header.Set("Authorization", "Bearer "+n.config.Token)
+	}
+	// the topic is part of the message, publishing JSON to the server root
+	_, err = publisher.PostJSON(ctx, n.config.ServerURL+"/", message{
+		Topic:    n.config.Topic,
+		Title:    push.Title(notification),
+		Message:  truncateBytes(notification.Message, maxMessage),
+		Priority: n.priorities.Of(notification),
+		Tags:     n.config.Tags,
+		Click:    click,
+	}, header)
+	return err
+}
+
+// truncateBytes shortens text to at most limit bytes without splitting characters
+func truncateBytes(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	const ellipsis = "…"
+	cut := limit - len(ellipsis)
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + ellipsis
+}
@@ -0,0 +1,83 @@
+package ntfy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func TestNtfyPublisherSend(t *testing.T) {
+	var authorization string
+	var received []message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var m message
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			t.Error(err)
+		}
+		received = append(received, m)
+	}))
+	defer server.Close()
+
+	p, err := NewNtfyPublisher(Config{
+		ServerURL:  server.URL + "/",
+		Topic:      "homelab-alerts",
+		Token:      "tk_secret",
+		Tags:       []string{"kubernetes"},
+		Priorities: []publisher.Rule[int]{{Type: corev1.EventTypeWarning, Value: 4}},
+		ClickURL:   "https://k8s.example.com/{{ .InvolvedObject.Namespace }}/{{ .InvolvedObject.Name }}",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "media"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "media", Name: "jellyfin-0"},
+		Reason:         "BackOff",
+		Type:           corev1.EventTypeWarning,
+	}
+	for _, n := range []publisher.Notification{
+		{Message: "jellyfin-0 is crash looping", Event: event},
+		{Message: strings.Repeat("é", 3000)},
+	} {
+		if err := p.Send(context.Background(), n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if authorization != "Bearer tk_secret" {
+		t.Errorf("unexpected authorization %q", authorization)
+	}
+	want := message{
+		Topic:    "homelab-alerts",
+		Title:    "BackOff: Pod media/jellyfin-0",
+		Message:  "jellyfin-0 is crash looping",
+		Priority: 4,
+		Tags:     []string{"kubernetes"},
+		Click:    "https://k8s.example.com/media/jellyfin-0",
+	}
+	if got := received[0]; got.Topic != want.Topic || got.Title != want.Title || got.Message != want.Message || got.Priority != want.Priority || got.Click != want.Click || len(got.Tags) != 1 {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	digest := received[1]
+	if digest.Priority != DefaultPriority || digest.Click != "" || len(digest.Message) > maxMessage || !strings.HasSuffix(digest.Message, "é…") {
+		t.Errorf("unexpected digest with priority %d and click %q", digest.Priority, digest.Click)
+	}
+
+	if _, err := NewNtfyPublisher(Config{Topic: "alerts", DefaultPriority: 6}); err == nil {
+		t.Error("expected priorities above 5 to be rejected")
+	}
+}
@@ -0,0 +1,164 @@
+package opsgenie
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+const (
+	DefaultAPIURL   = "https://api.opsgenie.com"
+	DefaultPriority = "P3"
+	// digestPriority is used for summaries, which are informational
+	digestPriority = "P5"
+)
+
+// Alert field limits, see https://docs.opsgenie.com/docs/alert-api#create-alert
+const (
+	maxMessage     = 130
+	maxAlias       = 512
+	maxDescription = 15000
+	maxSource      = 100
+	maxEntity      = 512
+	maxTag         = 50
+	maxTags        = 20
+)
+
+type Config struct {
+	APIURL          string
+	APIKey          string
+	Priorities      []publisher.Rule[string]
+	DefaultPriority string
+	Tags            []string
+}
+
+type Alert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Priority    string            `json:"priority,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+	Entity      string            `json:"entity,omitempty"`
+	Source      string            `json:"source,omitempty"`
+}
+
+type closeRequest struct {
+	Source string `json:"source,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+// OpsgeniePublisher creates alerts through the Opsgenie Alert API. Alerts are
+// aliased by involved object, so Opsgenie deduplicates repeated events and
+// Resolve closes the alert of the object.
+type OpsgeniePublisher struct {
+	Config
+}
+
+func NewOpsgeniePublisher(config Config) *OpsgeniePublisher {
+	if config.APIURL == "" {
+		config.APIURL = DefaultAPIURL
+	}
+	if config.DefaultPriority == "" {
+		config.DefaultPriority = DefaultPriority
+	}
+	return &OpsgeniePublisher{Config: config}
+}
+
+func (o *OpsgeniePublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	alert := o.buildAlert(notification)
+	if _, err := publisher.PostJSON(ctx, o.endpoint("/v2/alerts"), alert, o.header()); err != nil {
+		return fmt.Errorf("failed to create opsgenie alert: %w", err)
+	}
+	return nil
+}
+
+// Resolve closes the alert opened for the object of the notification event
+func (o *OpsgeniePublisher) Resolve(ctx context.Context, notification publisher.Notification) error {
+	if notification.Event == nil {
+		return nil
+	}
+
+	path := "/v2/alerts/" + url.PathEscape(Alias(notification)) + "/close?identifierType=alias"
+	request := closeRequest{
+		Source: source(notification),
+		Note:   fmt.Sprintf("Resolved by %s event: %s", notification.Event.Reason, notification.Event.Message),
+	}
+	if _, err := publisher.PostJSON(ctx, o.endpoint(path), request, o.header()); err != nil {
+		return fmt.Errorf("failed to close opsgenie alert: %w", err)
+	}
+	return nil
+}
+
+func (o *OpsgeniePublisher) buildAlert(notification publisher.Notification) Alert {
+	tags := make([]string, 0, len(o.Tags))
+	for _, tag := range o.Tags {
+		if len(tags) == maxTags {
+			break
+		}
+		tags = append(tags, publisher.Truncate(tag, maxTag))
+	}
+
+	event := notification.Event
+	if event == nil {
+		return Alert{
+			Message:     "Kubernetes event digest",
+			Description: publisher.Truncate(notification.Message, maxDescription),
+			Priority:    digestPriority,
+			Tags:        tags,
+			Source:      source(notification),
+		}
+	}
+
+	details := map[string]string{
+		"namespace": event.Namespace,
+		"kind":      event.InvolvedObject.Kind,
+		"name":      event.InvolvedObject.Name,
+		"reason":    event.Reason,
+		"type":      event.Type,
+		"count":     strconv.Itoa(int(event.Count)),
+	}
+	if notification.ClusterName != "" {
+		details["cluster"] = notification.ClusterName
+	}
+
+	return Alert{
+		Message:     publisher.Truncate(fmt.Sprintf("%s: %s %s/%s", event.Reason, event.InvolvedObject.Kind, event.Namespace, event.InvolvedObject.Name), maxMessage),
+		Alias:       Alias(notification),
+		Description: publisher.Truncate(event.Message, maxDescription),
+		Priority:    publisher.Match(o.Priorities, event, o.DefaultPriority),
+		Tags:        tags,
+		Details:     details,
+		Entity:      publisher.Truncate(publisher.ObjectKey(event), maxEntity),
+		Source:      source(notification),
+	}
+}
+
+func (o *OpsgeniePublisher) endpoint(path string) string {
+	return strings.TrimSuffix(o.APIURL, "/") + path
+}
+
+func (o *OpsgeniePublisher) header() http.Header {
+	return http.Header{"Authorization": []string{"GenieKey " + o.APIKey}}
+}
+
+// Alias identifies the alert of the notification object, prefixed by the cluster if known
+func Alias(notification publisher.Notification) string {
+	alias := publisher.ObjectKey(notification.Event)
+	if notification.ClusterName != "" {
+		alias = notification.ClusterName + "/" + alias
+	}
+	return publisher.Truncate(alias, maxAlias)
+}
+
+func source(notification publisher.Notification) string {
+	if notification.ClusterName != "" {
+		return publisher.Truncate(notification.ClusterName, maxSource)
+	}
+	return "kubernetes"
+}
@@ -0,0 +1,126 @@
+package opsgenie
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+type request struct {
+	path  string
+	query string
+	body  map[string]any
+}
+
+func fakeOpsgenie(t *testing.T) (*httptest.Server, *[]request) {
+	t.Helper()
+
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "GenieKey secret-key" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		requests = append(requests, request{path: r.URL.EscapedPath(), query: r.URL.RawQuery, body: body})
+
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"result": "Request will be processed", "requestId": "43a29c5c"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func event(eventType, reason string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "payments"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "payments", Name: "api-0"},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        "Back-off restarting failed container",
+	}
+}
+
+func TestOpsgeniePublisherPriorities(t *testing.T) {
+	p := NewOpsgeniePublisher(Config{
+		Priorities: []publisher.Rule[string]{
+			{Reason: "OOMKilling", Value: "P1"},
+			{Type: "Warning", Reason: "BackOff", Value: "P2"},
+			{Type: "Normal", Value: "P5"},
+		},
+	})
+
+	tests := []struct {
+		eventType, reason, want string
+	}{
+		{"Warning", "OOMKilling", "P1"},
+		{"Warning", "BackOff", "P2"},
+		{"Normal", "BackOff", "P5"},
+		{"Warning", "FailedMount", DefaultPriority},
+	}
+	for _, tt := range tests {
+		if got := p.buildAlert(publisher.Notification{Event: event(tt.eventType, tt.reason)}).Priority; got != tt.want {
+			t.Errorf("priority(%s, %s) = %s, want %s", tt.eventType, tt.reason, got, tt.want)
+		}
+	}
+}
+
+func TestOpsgeniePublisherSendAndResolve(t *testing.T) {
+	server, requests := fakeOpsgenie(t)
+	p := NewOpsgeniePublisher(Config{
+		APIURL:     server.URL,
+		APIKey:     "secret-key",
+		Priorities: []publisher.Rule[string]{{Type: "Warning", Value: "P2"}},
+		Tags:       []string{"k8s"},
+	})
+
+	ctx := context.Background()
+	if err := p.Send(ctx, publisher.Notification{Event: event("Warning", "BackOff"), ClusterName: "prod"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Resolve(ctx, publisher.Notification{Event: event("Normal", "Started"), ClusterName: "prod"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("expected 2 requests, got %+v", *requests)
+	}
+
+	create := (*requests)[0]
+	if create.path != "/v2/alerts" {
+		t.Errorf("unexpected create path %s", create.path)
+	}
+	if create.body["alias"] != "prod/payments/Pod/api-0" || create.body["priority"] != "P2" ||
+		create.body["message"] != "BackOff: Pod payments/api-0" || create.body["source"] != "prod" {
+		t.Errorf("unexpected alert %+v", create.body)
+	}
+
+	closeAlert := (*requests)[1]
+	if closeAlert.path != "/v2/alerts/prod%2Fpayments%2FPod%2Fapi-0/close" || closeAlert.query != "identifierType=alias" {
+		t.Errorf("unexpected close request %s?%s", closeAlert.path, closeAlert.query)
+	}
+}
+
+func TestOpsgeniePublisherError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message": "Could not authenticate"}`))
+	}))
+	defer server.Close()
+
+	p := NewOpsgeniePublisher(Config{APIURL: server.URL, APIKey: "wrong"})
+	if err := p.Send(context.Background(), publisher.Notification{Message: "digest"}); err == nil {
+		t.Error("expected an error for an unauthorized request")
+	}
+}
@@ -0,0 +1,352 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+	"github.com/example/notifier/pkg/publisher/batch"
+)
+
+// Protocols an exporter speaks
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http"
+)
+
+const (
+	logsPath  = "/v1/logs"
+	scopeName = "github.com/example/notifier"
+)
+
+var now = time.Now
+
+// Config defines the OTLP endpoint and the queue and retry settings of the exporter
+type Config struct {
+	// Endpoint of the collector, such as http://otel-collector:4317. The scheme
+	// selects plaintext or TLS, HTTP requests go to the /v1/logs path below it.
+	Endpoint string
+	// Protocol is grpc or http, which sends protobuf over HTTP
+	Protocol string
+	// Headers are sent with every export, such as an authorization header
+	Headers map[string]string
+	TLS     *publisher.TLSConfig
+	// BatchSize is the number of log records sent per export
+	BatchSize int
+	// FlushInterval is the longest a record waits for its batch to fill up
+	FlushInterval time.Duration
+	// QueueSize is the number of records that can wait to be exported
+	QueueSize int
+	// MaxAttempts bounds how often a batch is exported before it is dropped
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, doubled for each further one
+	InitialBackoff time.Duration
+}
+
+// record is a log record with the attributes of the resource it describes
+type record struct {
+	resource []*commonpb.KeyValue
+	log      *logspb.LogRecord
+}
+
+// exportFunc sends a request, returning whether a failure is worth retrying
+type exportFunc func(ctx context.Context, request *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, bool, error)
+
+// OTLPPublisher exports notifications as OTLP log records. The involved object
+// is described by resource attributes following the Kubernetes semantic conventions.
+type OTLPPublisher struct {
+	export  exportFunc
+	close   func() error
+	batcher *batch.Batcher[record]
+}
+
+func NewOTLPPublisher(config Config, onError func(error)) (*OTLPPublisher, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid otlp endpoint: %w", err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("otlp endpoint %q must be an http or https URL", config.Endpoint)
+	}
+
+	o := &OTLPPublisher{close: func() error { return nil }}
+	switch config.Protocol {
+	case ProtocolGRPC, "":
+		conn, err := dialGRPC(endpoint, config.TLS)
+		if err != nil {
+			return nil, err
+		}
+		o.export = grpcExporter(conn, config.Headers)
+		o.close = conn.Close
+	case ProtocolHTTP:
+		client, err := config.TLS.HTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		o.export = httpExporter(client, strings.TrimSuffix(config.Endpoint, "/")+logsPath, config.Headers)
+	default:
+		return nil, fmt.Errorf("unsupported otlp protocol %q", config.Protocol)
+	}
+
+	o.batcher = batch.NewWithOptions(batch.Options{
+		Size:        config.BatchSize,
+		Interval:    config.FlushInterval,
+		QueueSize:   config.QueueSize,
+		MaxAttempts: config.MaxAttempts,
+		Backoff:     config.InitialBackoff,
+	}, o.flush, onError)
+	return o, nil
+}
+
+func (o *OTLPPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	if err := o.batcher.Add(ctx, buildRecord(notification)); err != nil {
+		return fmt.Errorf("failed to queue otlp log record: %w", err)
+	}
+	return nil
+}
+
+// Close exports the queued records and closes the connection
+func (o *OTLPPublisher) Close() error {
+	err := o.batcher.Close()
+	return errors.Join(err, o.close())
+}
+
+func (o *OTLPPublisher) flush(ctx context.Context, records []record) ([]record, error) {
+	response, retryable, err := o.export(ctx, buildRequest(records))
+	if err != nil {
+		if retryable {
+			return records, fmt.Errorf("failed to export %d log records: %w", len(records), err)
+		}
+		return nil, fmt.Errorf("otlp endpoint rejected %d log records: %w", len(records), err)
+	}
+	// rejected records of a partial success must not be sent again
+	if partial := response.GetPartialSuccess(); partial.GetRejectedLogRecords() > 0 {
+		return nil, fmt.Errorf("otlp endpoint rejected %d of %d log records: %s", partial.GetRejectedLogRecords(), len(records), partial.GetErrorMessage())
+	}
+	return nil, nil
+}
+
+func dialGRPC(endpoint *url.URL, tlsConfig *publisher.TLSConfig) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if endpoint.Scheme == "https" {
+		config := &tls.Config{MinVersion: tls.VersionTLS12}
+		if tlsConfig != nil {
+			var err error
+			if config, err = tlsConfig.Build(); err != nil {
+				return nil, err
+			}
+		}
+		creds = credentials.NewTLS(config)
+	}
+	conn, err := grpc.NewClient(endpoint.Host, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create otlp client: %w", err)
+	}
+	return conn, nil
+}
+
+func grpcExporter(conn *grpc.ClientConn, headers map[string]string) exportFunc {
+	client := collogspb.NewLogsServiceClient(conn)
+	md := metadata.New(headers)
+	return func(ctx context.Context, request *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, bool, error) {
+		response, err := client.Export(metadata.NewOutgoingContext(ctx, md), request)
+		if err != nil {
+			switch status.Code(err) {
+			case codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted,
+				codes.OutOfRange, codes.Unavailable, codes.DataLoss:
+				return nil, true, err
+			}
+			return nil, false, err
+		}
+		return response, false, nil
+	}
+}
+
+func httpExporter(client *http.Client, endpoint string, headers map[string]string) exportFunc {
+	return func(ctx context.Context, request *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, bool, error) {
+		body, err := proto.Marshal(request)
+		if err != nil {
+			return nil, false, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, false, err
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		req.Header.Set("Content-Type", "application/x-protobuf")
+
+		responseBody, err := publisher.DoWith(client, req)
+		if err != nil {
+			var httpErr *publisher.HTTPError
+			if errors.As(err, &httpErr) {
+				switch httpErr.StatusCode {
+				case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+					return nil, true, err
+				}
+				return nil, false, err
+			}
+			return nil, true, err
+		}
+
+		response := &collogspb.ExportLogsServiceResponse{}
+		if err := proto.Unmarshal(responseBody, response); err != nil {
+			return nil, false, fmt.Errorf("invalid otlp response: %w", err)
+		}
+		return response, false, nil
+	}
+}
+
+// buildRequest groups the records by resource
+func buildRequest(records []record) *collogspb.ExportLogsServiceRequest {
+	request := &collogspb.ExportLogsServiceRequest{}
+	byResource := map[string]*logspb.ScopeLogs{}
+	for _, r := range records {
+		key := resourceKey(r.resource)
+		scope, ok := byResource[key]
+		if !ok {
+			scope = &logspb.ScopeLogs{Scope: &commonpb.InstrumentationScope{Name: scopeName}}
+			byResource[key] = scope
+			request.ResourceLogs = append(request.ResourceLogs, &logspb.ResourceLogs{
+				Resource:  &resourcepb.Resource{Attributes: r.resource},
+				ScopeLogs: []*logspb.ScopeLogs{scope},
+			})
+		}
+		scope.LogRecords = append(scope.LogRecords, r.log)
+	}
+	return request
+}
+
+func resourceKey(attributes []*commonpb.KeyValue) string {
+	var b strings.Builder
+	for _, attribute := range attributes {
+		b.WriteString(attribute.Key + "=" + attribute.Value.GetStringValue() + "\x00")
+	}
+	return b.String()
+}
+
+func buildRecord(notification publisher.Notification) record {
+	observedAt := now()
+	observed := uint64(observedAt.UnixNano())
+	resource := attributes(map[string]string{"k8s.cluster.name": notification.ClusterName})
+
+	event := notification.Event
+	if event == nil {
+		return record{
+			resource: resource,
+			log: &logspb.LogRecord{
+				TimeUnixNano:         observed,
+				ObservedTimeUnixNano: observed,
+				SeverityNumber:       logspb.SeverityNumber_SEVERITY_NUMBER_INFO,
+				SeverityText:         "Info",
+				Body:                 stringValue(notification.Message),
+			},
+		}
+	}
+
+	object := event.InvolvedObject
+	namespace := object.Namespace
+	if namespace == "" {
+		namespace = event.Namespace
+	}
+	resourceAttributes := map[string]string{
+		"k8s.cluster.name":   notification.ClusterName,
+		"k8s.namespace.name": namespace,
+	}
+	if kind := semconvKind(object.Kind); kind != "" {
+		resourceAttributes["k8s."+kind+".name"] = object.Name
+		resourceAttributes["k8s."+kind+".uid"] = string(object.UID)
+	}
+	// the owning controller, such as the deployment of a pod's replica set
+	if ownerKind, ownerName, ok := strings.Cut(notification.Owner, "/"); ok {
+		if kind := semconvKind(ownerKind); kind != "" {
+			if _, set := resourceAttributes["k8s."+kind+".name"]; !set {
+				resourceAttributes["k8s."+kind+".name"] = ownerName
+			}
+		}
+	}
+
+	timestamp := uint64(publisher.EventTime(event, observedAt).UnixNano())
+
+	severity, severityText := logspb.SeverityNumber_SEVERITY_NUMBER_INFO, corev1.EventTypeNormal
+	if event.Type == corev1.EventTypeWarning {
+		severity, severityText = logspb.SeverityNumber_SEVERITY_NUMBER_WARN, corev1.EventTypeWarning
+	}
+
+	log := &logspb.LogRecord{
+		TimeUnixNano:         timestamp,
+		ObservedTimeUnixNano: observed,
+		SeverityNumber:       severity,
+		SeverityText:         severityText,
+		Body:                 stringValue(event.Message),
+		Attributes: attributes(map[string]string{
+			"k8s.event.name":       event.Name,
+			"k8s.event.uid":        string(event.UID),
+			"k8s.event.reason":     event.Reason,
+			"k8s.event.action":     event.Action,
+			"k8s.object.kind":      object.Kind,
+			"k8s.object.name":      object.Name,
+			"k8s.object.fieldpath": object.FieldPath,
+		}),
+	}
+	if event.Count > 0 {
+		log.Attributes = append(log.Attributes, &commonpb.KeyValue{
+			Key:   "k8s.event.count",
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(event.Count)}},
+		})
+	}
+	return record{resource: attributes(resourceAttributes), log: log}
+}
+
+// semconvKind returns the attribute namespace of kinds with semantic conventions
+func semconvKind(kind string) string {
+	switch kind {
+	case "Pod", "Node", "Deployment", "ReplicaSet", "StatefulSet", "DaemonSet", "Job", "CronJob":
+		return strings.ToLower(kind)
+	default:
+		return ""
+	}
+}
+
+// attributes converts the non-empty values to string attributes, sorted by key
+func attributes(values map[string]string) []*commonpb.KeyValue {
+	keys := make([]string, 0, len(values))
+	for key, value := range values {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	kvs := make([]*commonpb.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		kvs = append(kvs, &commonpb.KeyValue{Key: key, Value: stringValue(values[key])})
+	}
+	return kvs
+}
+
+func stringValue(s string) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}}
+}
@@ -0,0 +1,232 @@
+package otlp
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func backOffEvent(name string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop", Name: name + ".17c", UID: "e1"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: name, UID: "p1"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Type:           corev1.EventTypeWarning,
+		Count:          3,
+		LastTimestamp:  metav1.NewTime(time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)),
+	}
+}
+
+func attributeMap(kvs []*commonpb.KeyValue) map[string]string {
+	m := map[string]string{}
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value.GetStringValue()
+	}
+	return m
+}
+
+type logsServer struct {
+	collogspb.UnimplementedLogsServiceServer
+	requests chan *collogspb.ExportLogsServiceRequest
+	headers  chan metadata.MD
+	err      error
+}
+
+func (s *logsServer) Export(ctx context.Context, request *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.headers <- md
+	s.requests <- request
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+// serveLogs starts a collector, returning its endpoint
+func serveLogs(t *testing.T, logs *logsServer) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	collogspb.RegisterLogsServiceServer(server, logs)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return "http://" + listener.Addr().String()
+}
+
+func TestOTLPPublisherGRPC(t *testing.T) {
+	logs := &logsServer{requests: make(chan *collogspb.ExportLogsServiceRequest, 1), headers: make(chan metadata.MD, 1)}
+	p, err := NewOTLPPublisher(Config{
+		Endpoint: serveLogs(t, logs),
+		Headers:  map[string]string{"authorization": "Bearer token"},
+	}, func(err error) {
+		t.Errorf("unexpected error: %v", err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, n := range []publisher.Notification{
+		{Event: backOffEvent("cart-0"), ClusterName: "prod", Owner: "StatefulSet/cart"},
+		{Event: backOffEvent("cart-0"), ClusterName: "prod", Owner: "StatefulSet/cart"},
+		{Message: "3 events", ClusterName: "prod"},
+	} {
+		if err := p.Send(ctx, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if md := <-logs.headers; len(md.Get("authorization")) != 1 || md.Get("authorization")[0] != "Bearer token" {
+		t.Errorf("unexpected metadata %v", md)
+	}
+	request := <-logs.requests
+	if len(request.ResourceLogs) != 2 {
+		t.Fatalf("expected the pod and the cluster as resources, got %d", len(request.ResourceLogs))
+	}
+
+	pod := request.ResourceLogs[0]
+	resource := attributeMap(pod.Resource.Attributes)
+	for key, value := range map[string]string{
+		"k8s.cluster.name":     "prod",
+		"k8s.namespace.name":   "shop",
+		"k8s.pod.name":         "cart-0",
+		"k8s.pod.uid":          "p1",
+		"k8s.statefulset.name": "cart",
+	} {
+		if resource[key] != value {
+			t.Errorf("expected resource attribute %s=%s, got %v", key, value, resource)
+		}
+	}
+	records := pod.ScopeLogs[0].LogRecords
+	if len(records) != 2 {
+		t.Fatalf("expected both records under the pod, got %d", len(records))
+	}
+	record := records[0]
+	if record.SeverityNumber != logspb.SeverityNumber_SEVERITY_NUMBER_WARN || record.SeverityText != "Warning" {
+		t.Errorf("unexpected severity %v %s", record.SeverityNumber, record.SeverityText)
+	}
+	if record.TimeUnixNano != uint64(time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC).UnixNano()) || record.ObservedTimeUnixNano == 0 {
+		t.Errorf("unexpected timestamps %d %d", record.TimeUnixNano, record.ObservedTimeUnixNano)
+	}
+	if record.Body.GetStringValue() != "Back-off restarting failed container" {
+		t.Errorf("unexpected body %v", record.Body)
+	}
+	if attributes := attributeMap(record.Attributes); attributes["k8s.event.reason"] != "BackOff" || attributes["k8s.object.kind"] != "Pod" {
+		t.Errorf("unexpected attributes %v", attributes)
+	}
+
+	digest := request.ResourceLogs[1]
+	if resource := attributeMap(digest.Resource.Attributes); len(resource) != 1 || resource["k8s.cluster.name"] != "prod" {
+		t.Errorf("unexpected digest resource %v", resource)
+	}
+	if body := digest.ScopeLogs[0].LogRecords[0].Body.GetStringValue(); body != "3 events" {
+		t.Errorf("unexpected digest body %s", body)
+	}
+}
+
+func TestOTLPPublisherHTTP(t *testing.T) {
+	statusCode, response := http.StatusOK, []byte(nil)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != logsPath || r.Header.Get("Content-Type") != "application/x-protobuf" || r.Header.Get("X-Tenant") != "team-a" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := proto.Unmarshal(body, &collogspb.ExportLogsServiceRequest{}); err != nil {
+			t.Error(err)
+		}
+		requests++
+		w.WriteHeader(statusCode)
+		_, _ = w.Write(response)
+	}))
+	defer server.Close()
+
+	p, err := NewOTLPPublisher(Config{Endpoint: server.URL + "/", Protocol: ProtocolHTTP, Headers: map[string]string{"X-Tenant": "team-a"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = p.Close() }()
+
+	records := []record{buildRecord(publisher.Notification{Event: backOffEvent("cart-0")})}
+	if retry, err := p.flush(context.Background(), records); err != nil || len(retry) != 0 {
+		t.Fatalf("unexpected result %d: %v", len(retry), err)
+	}
+
+	response, _ = proto.Marshal(&collogspb.ExportLogsServiceResponse{
+		PartialSuccess: &collogspb.ExportLogsPartialSuccess{RejectedLogRecords: 1, ErrorMessage: "too old"},
+	})
+	if retry, err := p.flush(context.Background(), records); err == nil || len(retry) != 0 {
+		t.Errorf("expected the partial success to be reported without retries, got %d: %v", len(retry), err)
+	}
+
+	for _, tc := range []struct {
+		status int
+		retry  int
+	}{
+		{http.StatusServiceUnavailable, 1},
+		{http.StatusTooManyRequests, 1},
+		{http.StatusBadRequest, 0},
+	} {
+		statusCode, response = tc.status, nil
+		retry, err := p.flush(context.Background(), records)
+		if err == nil || len(retry) != tc.retry {
+			t.Errorf("status %d: expected %d records to be retried, got %d: %v", tc.status, tc.retry, len(retry), err)
+		}
+	}
+	if requests != 5 {
+		t.Errorf("expected 5 requests, got %d", requests)
+	}
+}
+
+func TestOTLPPublisherGRPCFailures(t *testing.T) {
+	logs := &logsServer{}
+	p, err := NewOTLPPublisher(Config{Endpoint: serveLogs(t, logs)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = p.Close() }()
+
+	records := []record{buildRecord(publisher.Notification{Message: "3 events"})}
+	for _, tc := range []struct {
+		code  codes.Code
+		retry int
+	}{
+		{codes.Unavailable, 1},
+		{codes.ResourceExhausted, 1},
+		{codes.InvalidArgument, 0},
+		{codes.Unauthenticated, 0},
+	} {
+		logs.err = status.Error(tc.code, "failed")
+		retry, err := p.flush(context.Background(), records)
+		if err == nil || len(retry) != tc.retry {
+			t.Errorf("%s: expected %d records to be retried, got %d: %v", tc.code, tc.retry, len(retry), err)
+		}
+	}
+
+	if _, err := NewOTLPPublisher(Config{Endpoint: "otel-collector:4317"}, nil); err == nil {
+		t.Error("expected endpoints without a scheme to be rejected")
+	}
+}
@@ -0,0 +1,92 @@
+package push
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+// DigestTitle is the title of digest notifications
+const DigestTitle = "Kubernetes event digest"
+
+// Priorities picks the priority of notifications from the first matching rule
+type Priorities struct {
+	Rules   []publisher.Rule[int]
+	Default int
+}
+
+// Of returns the priority of the notification, the default for digests
+func (p Priorities) Of(notification publisher.Notification) int {
+	return publisher.Match(p.Rules, notification.Event, p.Default)
+}
+
+// Validate checks that every priority is within the range of the service
+func (p Priorities) Validate(low, high int) error {
+	priorities := []int{p.Default}
+	for _, rule := range p.Rules {
+		priorities = append(priorities, rule.Value)
+	}
+	for _, priority := range priorities {
+		if priority < low || priority > high {
+			return fmt.Errorf("invalid priority %d, expected %d to %d", priority, low, high)
+		}
+	}
+	return nil
+}
+
+// ClickURL is the page opened when a notification is tapped
+type ClickURL struct {
+	template *template.Template
+}
+
+// ParseClickURL parses a template evaluated against the event, such as
+// https://grafana.example.com/d/pods?var-pod={{ .InvolvedObject.Name }}
+func ParseClickURL(text string) (*ClickURL, error) {
+	if text == "" {
+		return &ClickURL{}, nil
+	}
+	t, err := template.New("clickURL").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid click URL template: %w", err)
+	}
+	return &ClickURL{template: t}, nil
+}
+
+// Render returns the URL of the notification, empty for digests or if no template is set
+func (c *ClickURL) Render(notification publisher.Notification) (string, error) {
+	if c.template == nil || notification.Event == nil {
+		return "", nil
+	}
+	var b strings.Builder
+	if err := c.template.Execute(&b, publisher.NewTemplateData(notification)); err != nil {
+		return "", fmt.Errorf("failed to render click URL: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// Title summarizes what happened to which object, prefixed by the cluster if set
+func Title(notification publisher.Notification) string {
+	title := DigestTitle
+	if event := notification.Event; event != nil {
+		name := event.InvolvedObject.Name
+		if namespace := namespace(event); namespace != "" {
+			name = namespace + "/" + name
+		}
+		title = fmt.Sprintf("%s: %s %s", event.Reason, event.InvolvedObject.Kind, name)
+	}
+	if notification.ClusterName != "" {
+		title = "[" + notification.ClusterName + "] " + title
+	}
+	return title
+}
+
+func namespace(event *corev1.Event) string {
+	if event.InvolvedObject.Namespace != "" {
+		return event.InvolvedObject.Namespace
+	}
+	return event.Namespace
+}
@@ -0,0 +1,75 @@
+package push
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func event(eventType, reason string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "media"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "jellyfin-0"},
+		Type:           eventType,
+		Reason:         reason,
+	}
+}
+
+func TestPriorities(t *testing.T) {
+	priorities := Priorities{
+		Rules: []publisher.Rule[int]{
+			{Reason: "OOMKilling", Value: 5},
+			{Type: corev1.EventTypeWarning, Value: 4},
+		},
+		Default: 2,
+	}
+	for _, test := range []struct {
+		notification publisher.Notification
+		priority     int
+	}{
+		{publisher.Notification{Event: event(corev1.EventTypeWarning, "OOMKilling")}, 5},
+		{publisher.Notification{Event: event(corev1.EventTypeWarning, "BackOff")}, 4},
+		{publisher.Notification{Event: event(corev1.EventTypeNormal, "Pulled")}, 2},
+		{publisher.Notification{Message: "3 events"}, 2},
+	} {
+		if priority := priorities.Of(test.notification); priority != test.priority {
+			t.Errorf("expected priority %d for %v, got %d", test.priority, test.notification.Event, priority)
+		}
+	}
+
+	if err := priorities.Validate(1, 5); err != nil {
+		t.Error(err)
+	}
+	if err := priorities.Validate(0, 4); err == nil {
+		t.Error("expected rule priorities out of range to be rejected")
+	}
+}
+
+func TestClickURL(t *testing.T) {
+	click, err := ParseClickURL("https://grafana.example.com/d/pods?var-namespace={{ .InvolvedObject.Namespace }}&var-pod={{ .InvolvedObject.Name }}&var-cluster={{ .ClusterName }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notification := publisher.Notification{Event: event(corev1.EventTypeWarning, "BackOff"), ClusterName: "homelab"}
+	notification.Event.InvolvedObject.Namespace = "media"
+	url, err := click.Render(notification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://grafana.example.com/d/pods?var-namespace=media&var-pod=jellyfin-0&var-cluster=homelab" {
+		t.Errorf("unexpected url %q", url)
+	}
+	if url, _ := click.Render(publisher.Notification{Message: "3 events"}); url != "" {
+		t.Errorf("expected no url for digests, got %q", url)
+	}
+
+	if title := Title(notification); title != "[homelab] BackOff: Pod media/jellyfin-0" {
+		t.Errorf("unexpected title %q", title)
+	}
+	if _, err := ParseClickURL("{{ .Reason "); err == nil {
+		t.Error("expected invalid templates to be rejected")
+	}
+}
@@ -0,0 +1,116 @@
+package pushover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/example/notifier/pkg/publisher"
+	"github.com/example/notifier/pkg/publisher/push"
+)
+
+const (
+	DefaultAPIURL = "https://api.pushover.net"
+
+	// Priorities range from lowest to emergency, which is repeated until acknowledged
+	minPriority       = -2
+	maxPriority       = 2
+	emergencyPriority = 2
+	// emergencyRetry and emergencyExpire set how often and how long emergencies are repeated
+	emergencyRetry  = time.Minute
+	emergencyExpire = time.Hour
+)
+
+// Message field limits, see https://pushover.net/api#limits
+const (
+	maxTitle   = 250
+	maxMessage = 1024
+	maxURL     = 512
+)
+
+// Config defines the application notifications are sent by and the user or group receiving them
+type Config struct {
+	APIURL string
+	// Token is the API token of the application
+	Token string
+	// UserKey is the key of the user or group receiving notifications
+	UserKey string
+	// Device limits notifications to a device of the user, all devices if empty
+	Device     string
+	Sound      string
+	Priorities []publisher.Rule[int]
+	// DefaultPriority applies if no rule matches
+	DefaultPriority int
+	// ClickURL is a template of the supplementary URL shown with the notification
+	ClickURL string
+}
+
+type message struct {
+	Token    string `json:"token"`
+	User     string `json:"user"`
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Priority int    `json:"priority"`
+	Retry    int    `json:"retry,omitempty"`
+	Expire   int    `json:"expire,omitempty"`
+	Device   string `json:"device,omitempty"`
+	Sound    string `json:"sound,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// PushoverPublisher sends notifications through the Pushover Message API
+type PushoverPublisher struct {
+	config     Config
+	priorities push.Priorities
+	click      *push.ClickURL
+}
+
+func NewPushoverPublisher(config Config) (*PushoverPublisher, error) {
+	if config.APIURL == "" {
+		config.APIURL = DefaultAPIURL
+	}
+	config.APIURL = strings.TrimSuffix(config.APIURL, "/")
+	if config.Token == "" || config.UserKey == "" {
+		return nil, errors.New("a pushover application token and user key are required")
+	}
+
+	priorities := push.Priorities{Rules: config.Priorities, Default: config.DefaultPriority}
+	if err := priorities.Validate(minPriority, maxPriority); err != nil {
+		return nil, fmt.Errorf("pushover: %w", err)
+	}
+	click, err := push.ParseClickURL(config.ClickURL)
+	if err != nil {
+		return nil, err
+	}
+	return &PushoverPublisher{config: config, priorities: priorities, click: click}, nil
+}
+
+func (p *PushoverPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	click, err := p.click.Render(notification)
+	if err != nil {
+		return err
+	}
+
+	msg := message{
+		Token:    p.config.Token,
+		User:     p.config.UserKey,
+		Title:    publisher.Truncate(push.Title(notification), maxTitle),
+		Message:  publisher.Truncate(notification.Message, maxMessage),
+		Priority: p.priorities.Of(notification),
+		Device:   p.config.Device,
+		Sound:    p.config.Sound,
+	}
+	if msg.Priority == emergencyPriority {
+		msg.Retry = int(emergencyRetry.Seconds())
+		msg.Expire = int(emergencyExpire.Seconds())
+	}
+	// longer URLs are rejected, so they are left out rather than cut
+	if click != "" && len(click) <= maxURL {
+		msg.URL = click
+	}
+
+	_, err = publisher.PostJSON(ctx, p.config.APIURL+"/1/messages.json", msg, nil)
+	return err
+}
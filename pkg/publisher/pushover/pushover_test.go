@@ -0,0 +1,77 @@
+package pushover
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func TestPushoverPublisherSend(t *testing.T) {
+	var received []message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1/messages.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var m message
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			t.Error(err)
+		}
+		if m.Token != "app-token" || m.User != "user-key" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":0,"errors":["application token is invalid"]}`))
+			return
+		}
+		received = append(received, m)
+		_, _ = w.Write([]byte(`{"status":1}`))
+	}))
+	defer server.Close()
+
+	p, err := NewPushoverPublisher(Config{
+		APIURL:     server.URL,
+		Token:      "app-token",
+		UserKey:    "user-key",
+		Sound:      "siren",
+		Priorities: []publisher.Rule[int]{{Reason: "OOMKilling", Value: 2}, {Type: corev1.EventTypeWarning, Value: 1}},
+		ClickURL:   "https://k8s.example.com/{{ .InvolvedObject.Name }}{{ if eq .Reason \"Long\" }}/" + strings.Repeat("x", 600) + "{{ end }}",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, reason := range []string{"OOMKilling", "BackOff", "Long"} {
+		event := &corev1.Event{InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "home", Name: "hass-0"}, Reason: reason, Type: corev1.EventTypeWarning}
+		if err := p.Send(context.Background(), publisher.Notification{Message: strings.Repeat("m", 2000), Event: event}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	emergency := received[0]
+	if emergency.Priority != 2 || emergency.Retry != 60 || emergency.Expire != 3600 || emergency.Sound != "siren" {
+		t.Errorf("expected an emergency repeated until acknowledged, got %+v", emergency)
+	}
+	if high := received[1]; high.Priority != 1 || high.Retry != 0 || high.URL != "https://k8s.example.com/hass-0" || high.Title != "BackOff: Pod home/hass-0" {
+		t.Errorf("unexpected message %+v", high)
+	}
+	if n := len([]rune(received[1].Message)); n != maxMessage {
+		t.Errorf("expected the message to be cut to %d characters, got %d", maxMessage, n)
+	}
+	if received[2].URL != "" {
+		t.Errorf("expected URLs over the limit to be left out, got %q", received[2].URL)
+	}
+
+	invalid, _ := NewPushoverPublisher(Config{APIURL: server.URL, Token: "revoked", UserKey: "user-key"})
+	if err := invalid.Send(context.Background(), publisher.Notification{Message: "3 events"}); err == nil {
+		t.Error("expected rejected messages to fail")
+	}
+	if _, err := NewPushoverPublisher(Config{Token: "app-token", UserKey: "user-key", DefaultPriority: 3}); err == nil {
+		t.Error("expected priorities above emergency to be rejected")
+	}
+}
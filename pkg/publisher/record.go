@@ -0,0 +1,63 @@
+package publisher
+
+import (
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Record is the JSON document message bus channels publish for every notification
+type Record struct {
+	Cluster string `json:"cluster,omitempty"`
+	Owner   string `json:"owner,omitempty"`
+	// Message is the digest text, events are sent as is
+	Message string        `json:"message,omitempty"`
+	Event   *corev1.Event `json:"event,omitempty"`
+}
+
+func NewRecord(notification Notification) Record {
+	record := Record{Cluster: notification.ClusterName, Owner: notification.Owner, Event: notification.Event}
+	if notification.Event == nil {
+		record.Message = notification.Message
+	}
+	return record
+}
+
+// TemplateData is what templates over a notification event are evaluated against,
+// the event fields with the owner and cluster added
+type TemplateData struct {
+	*corev1.Event
+	Owner       string
+	ClusterName string
+}
+
+func NewTemplateData(notification Notification) TemplateData {
+	return TemplateData{Event: notification.Event, Owner: notification.Owner, ClusterName: notification.ClusterName}
+}
+
+// EventTime is when the event last occurred: its last timestamp, the event time
+// set by the events.k8s.io API, or fallback if neither is set
+func EventTime(event *corev1.Event, fallback time.Time) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return fallback
+	}
+}
+
+// EventID identifies an occurrence of an event: the event UID, suffixed by the count
+// for repeated events, so receivers deduplicate retries but not repetitions
+func EventID(event *corev1.Event) string {
+	id := string(event.UID)
+	if id == "" {
+		id = event.Namespace + "/" + event.Name
+	}
+	if event.Count > 1 {
+		id += "-" + strconv.Itoa(int(event.Count))
+	}
+	return id
+}
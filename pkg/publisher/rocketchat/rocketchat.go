@@ -0,0 +1,127 @@
+package rocketchat
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+	"github.com/example/notifier/pkg/publisher/slack"
+)
+
+const (
+	maxText       = 5000
+	maxTitle      = 256
+	maxFieldValue = 1024
+)
+
+const (
+	colorWarning = "#E67E22"
+	colorNormal  = "#2ECC71"
+	colorOther   = "#95A5A6"
+)
+
+// Payload is a Rocket.Chat incoming webhook request,
+// see https://docs.rocket.chat/docs/integrations
+type Payload struct {
+	Text        string       `json:"text,omitempty"`
+	Channel     string       `json:"channel,omitempty"`
+	Alias       string       `json:"alias,omitempty"`
+	Avatar      string       `json:"avatar,omitempty"`
+	Emoji       string       `json:"emoji,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+type Attachment struct {
+	Title  string  `json:"title,omitempty"`
+	Text   string  `json:"text,omitempty"`
+	Color  string  `json:"color,omitempty"`
+	Fields []Field `json:"fields,omitempty"`
+}
+
+type Field struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// Overrides replace the defaults configured on the incoming webhook integration
+type Overrides struct {
+	// Channel is a #channel or @user
+	Channel string
+	// Username is shown as the message alias
+	Username  string
+	IconURL   string
+	IconEmoji string
+}
+
+type RocketChatPublisher struct {
+	WebhookURL string
+	Overrides
+}
+
+func NewRocketChatPublisher(webhookURL string, overrides Overrides) *RocketChatPublisher {
+	return &RocketChatPublisher{WebhookURL: webhookURL, Overrides: overrides}
+}
+
+func (r *RocketChatPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	payload := buildPayload(notification)
+	payload.Channel = r.Channel
+	payload.Alias = r.Username
+	payload.Avatar = r.IconURL
+	payload.Emoji = r.IconEmoji
+
+	if err := slack.PostWebhook(ctx, r.WebhookURL, payload); err != nil {
+		return fmt.Errorf("rocket.chat: %w", err)
+	}
+	return nil
+}
+
+func buildPayload(notification publisher.Notification) Payload {
+	event := notification.Event
+	if event == nil {
+		return Payload{Text: publisher.Truncate(notification.Message, maxText)}
+	}
+
+	title := fmt.Sprintf("%s: %s %s", event.Reason, event.InvolvedObject.Kind, event.InvolvedObject.Name)
+	attachment := Attachment{
+		Title: publisher.Truncate(title, maxTitle),
+		Text:  publisher.Truncate(event.Message, maxText),
+		Color: color(event.Type),
+		Fields: []Field{
+			field("Namespace", event.Namespace),
+			field("Kind", event.InvolvedObject.Kind),
+			field("Name", event.InvolvedObject.Name),
+			field("Type", event.Type),
+		},
+	}
+	if event.Count > 1 {
+		attachment.Fields = append(attachment.Fields, field("Count", strconv.Itoa(int(event.Count))))
+	}
+	if notification.ClusterName != "" {
+		attachment.Fields = append(attachment.Fields, field("Cluster", notification.ClusterName))
+	}
+
+	// the text is what shows up in mobile push notifications
+	return Payload{Text: publisher.Truncate(title, maxTitle), Attachments: []Attachment{attachment}}
+}
+
+func field(title, value string) Field {
+	if value == "" {
+		value = "-"
+	}
+	return Field{Title: title, Value: publisher.Truncate(value, maxFieldValue), Short: true}
+}
+
+func color(eventType string) string {
+	switch eventType {
+	case corev1.EventTypeWarning:
+		return colorWarning
+	case corev1.EventTypeNormal:
+		return colorNormal
+	default:
+		return colorOther
+	}
+}
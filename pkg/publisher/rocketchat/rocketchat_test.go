@@ -0,0 +1,73 @@
+package rocketchat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func TestRocketChatPublisherSend(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	event := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "worker-1"},
+		Reason:         "NodeNotReady",
+		Message:        "Node worker-1 status is now: NodeNotReady",
+		Type:           corev1.EventTypeNormal,
+		Count:          4,
+	}
+	p := NewRocketChatPublisher(server.URL, Overrides{
+		Channel:   "#k8s-alerts",
+		Username:  "notifier",
+		IconURL:   "https://example.com/icon.png",
+		IconEmoji: ":bell:",
+	})
+	if err := p.Send(context.Background(), publisher.Notification{Event: event, ClusterName: "air-gapped"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rocket.Chat names the overrides differently from Slack and Mattermost
+	if payload["channel"] != "#k8s-alerts" || payload["alias"] != "notifier" ||
+		payload["avatar"] != "https://example.com/icon.png" || payload["emoji"] != ":bell:" {
+		t.Errorf("expected native overrides in payload, got %+v", payload)
+	}
+	if payload["text"] != "NodeNotReady: Node worker-1" {
+		t.Errorf("unexpected text %v", payload["text"])
+	}
+
+	attachment := payload["attachments"].([]any)[0].(map[string]any)
+	if attachment["color"] != colorNormal || attachment["text"] != event.Message {
+		t.Errorf("unexpected attachment %+v", attachment)
+	}
+	fields := attachment["fields"].([]any)
+	last := fields[len(fields)-1].(map[string]any)
+	if len(fields) != 6 || last["title"] != "Cluster" || last["value"] != "air-gapped" {
+		t.Errorf("unexpected fields %+v", fields)
+	}
+}
+
+func TestRocketChatPublisherError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	if err := NewRocketChatPublisher(server.URL, Overrides{}).Send(context.Background(), publisher.Notification{Message: "digest"}); err == nil {
+		t.Error("expected an error for a missing integration")
+	}
+}
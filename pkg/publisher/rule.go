@@ -0,0 +1,32 @@
+package publisher
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Rule maps events of a type and reason to a value, such as the priority of an
+// alert. Empty fields match anything.
+type Rule[T any] struct {
+	Type   string
+	Reason string
+	Value  T
+}
+
+// Matches reports whether the rule applies to event
+func (r Rule[T]) Matches(event *corev1.Event) bool {
+	return (r.Type == "" || r.Type == event.Type) && (r.Reason == "" || r.Reason == event.Reason)
+}
+
+// Match returns the value of the first rule matching event, or fallback if none
+// does or event is nil, as it is for digests
+func Match[T any](rules []Rule[T], event *corev1.Event, fallback T) T {
+	if event == nil {
+		return fallback
+	}
+	for _, rule := range rules {
+		if rule.Matches(event) {
+			return rule.Value
+		}
+	}
+	return fallback
+}
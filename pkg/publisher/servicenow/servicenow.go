@@ -0,0 +1,280 @@
+package servicenow
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+// Urgency and impact levels of an incident
+const (
+	High   = 1
+	Medium = 2
+	Low    = 3
+)
+
+const (
+	incidentPath = "/api/now/table/incident"
+	tokenPath    = "/oauth_token.do"
+	// correlationDisplay names the source of correlated incidents
+	correlationDisplay = "k8s-event-notifier"
+	// tokenExpiryMargin renews OAuth tokens before they expire
+	tokenExpiryMargin = time.Minute
+)
+
+// Incident field limits of ServiceNow
+const (
+	maxShortDescription = 160
+	maxDescription      = 4000
+	maxCorrelationID    = 100
+)
+
+var now = time.Now
+
+// Severity is the urgency and impact of an incident
+type Severity struct {
+	Urgency int
+	Impact  int
+}
+
+// OAuth defines the client requesting access tokens. With a username and
+// password the password grant is used, the client credentials grant otherwise.
+type OAuth struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// Config defines the instance and how incidents are raised in it
+type Config struct {
+	// URL of the instance, such as https://example.service-now.com
+	URL      string
+	Username string
+	Password string
+	OAuth    *OAuth
+	// AssignmentGroup is the name or sys_id of the group incidents are assigned to
+	AssignmentGroup string
+	Category        string
+	// CallerID is the name or sys_id of the user incidents are raised for
+	CallerID string
+	// Severities are matched in order, the defaults apply if none matches
+	Severities     []publisher.Rule[Severity]
+	DefaultUrgency int
+	DefaultImpact  int
+}
+
+// ServiceNowPublisher raises incidents through the Table API. Events of the same
+// condition share a correlation ID, repetitions are added to the active incident
+// as work notes. Digests are not sent.
+type ServiceNowPublisher struct {
+	config Config
+	tokens *TokenCache
+}
+
+// TokenCache keeps OAuth access tokens across publishers, so that a publisher
+// created on every reconcile reuses the token of its client until it is about
+// to expire. The zero value is ready to use.
+type TokenCache struct {
+	mu     sync.Mutex
+	tokens map[[sha256.Size]byte]accessToken
+}
+
+type accessToken struct {
+	token  string
+	expiry time.Time
+}
+
+type incident struct {
+	SysID  string `json:"sys_id"`
+	Number string `json:"number"`
+}
+
+type tableResponse struct {
+	Result []incident `json:"result"`
+}
+
+// NewServiceNowPublisher creates a publisher, taking OAuth access tokens from
+// tokens. A nil cache keeps them for the lifetime of the publisher only.
+func NewServiceNowPublisher(config Config, tokens *TokenCache) *ServiceNowPublisher {
+	if config.DefaultUrgency == 0 {
+		config.DefaultUrgency = Low
+	}
+	if config.DefaultImpact == 0 {
+		config.DefaultImpact = Low
+	}
+	config.URL = strings.TrimSuffix(config.URL, "/")
+	if tokens == nil {
+		tokens = &TokenCache{}
+	}
+	return &ServiceNowPublisher{config: config, tokens: tokens}
+}
+
+func (s *ServiceNowPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	event := notification.Event
+	if event == nil {
+		return nil
+	}
+
+	correlationID := CorrelationID(notification)
+	active, err := s.activeIncident(ctx, correlationID)
+	if err != nil {
+		return err
+	}
+	if active != nil {
+		note := fmt.Sprintf("Occurred again (count %d): %s", event.Count, notification.Message)
+		if _, err := s.request(ctx, http.MethodPatch, incidentPath+"/"+url.PathEscape(active.SysID), map[string]string{"work_notes": note}); err != nil {
+			return fmt.Errorf("failed to update servicenow incident %s: %w", active.Number, err)
+		}
+		return nil
+	}
+
+	urgency, impact := s.severity(event)
+	fields := map[string]string{
+		"short_description":   publisher.Truncate(summary(event), maxShortDescription),
+		"description":         publisher.Truncate(notification.Message, maxDescription),
+		"urgency":             fmt.Sprint(urgency),
+		"impact":              fmt.Sprint(impact),
+		"correlation_id":      correlationID,
+		"correlation_display": correlationDisplay,
+		"assignment_group":    s.config.AssignmentGroup,
+		"category":            s.config.Category,
+		"caller_id":           s.config.CallerID,
+	}
+	for name, value := range fields {
+		if value == "" {
+			delete(fields, name)
+		}
+	}
+	if _, err := s.request(ctx, http.MethodPost, incidentPath, fields); err != nil {
+		return fmt.Errorf("failed to create servicenow incident: %w", err)
+	}
+	return nil
+}
+
+func summary(event *corev1.Event) string {
+	namespace := event.InvolvedObject.Namespace
+	if namespace == "" {
+		namespace = event.Namespace
+	}
+	return fmt.Sprintf("%s: %s %s/%s", event.Reason, event.InvolvedObject.Kind, namespace, event.InvolvedObject.Name)
+}
+
+func (s *ServiceNowPublisher) severity(event *corev1.Event) (int, int) {
+	severity := publisher.Match(s.config.Severities, event, Severity{Urgency: s.config.DefaultUrgency, Impact: s.config.DefaultImpact})
+	return severity.Urgency, severity.Impact
+}
+
+// activeIncident returns the active incident with the correlation ID, nil if there is none
+func (s *ServiceNowPublisher) activeIncident(ctx context.Context, correlationID string) (*incident, error) {
+	query := url.Values{
+		"sysparm_query":  {"active=true^correlation_id=" + correlationID + "^ORDERBYDESCsys_created_on"},
+		"sysparm_fields": {"sys_id,number"},
+		"sysparm_limit":  {"1"},
+	}
+	body, err := s.request(ctx, http.MethodGet, incidentPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search servicenow incidents: %w", err)
+	}
+	var response tableResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid servicenow response: %w", err)
+	}
+	if len(response.Result) == 0 {
+		return nil, nil
+	}
+	return &response.Result[0], nil
+}
+
+func (s *ServiceNowPublisher) request(ctx context.Context, method, path string, payload any) ([]byte, error) {
+	var body []byte
+	if payload != nil {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.config.URL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.config.OAuth != nil {
+		token, err := s.tokens.get(ctx, s.config)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else {
+		req.SetBasicAuth(s.config.Username, s.config.Password)
+	}
+	return publisher.Do(req)
+}
+
+// get returns the OAuth access token of the client in config, requesting a new
+// one once it is about to expire. Tokens are keyed by the instance and credentials,
+// so rotated credentials get a new token.
+func (c *TokenCache) get(ctx context.Context, config Config) (string, error) {
+	key := sha256.Sum256([]byte(strings.Join([]string{
+		config.URL, config.OAuth.ClientID, config.OAuth.ClientSecret, config.Username, config.Password,
+	}, "\x00")))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if token, ok := c.tokens[key]; ok && token.expiry.Sub(now()) > tokenExpiryMargin {
+		return token.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {config.OAuth.ClientID},
+		"client_secret": {config.OAuth.ClientSecret},
+	}
+	if config.Username != "" {
+		form.Set("grant_type", "password")
+		form.Set("username", config.Username)
+		form.Set("password", config.Password)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.URL+tokenPath, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	body, err := publisher.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request servicenow access token: %w", err)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("invalid servicenow access token response: %s", body)
+	}
+	if c.tokens == nil {
+		c.tokens = make(map[[sha256.Size]byte]accessToken)
+	}
+	c.tokens[key] = accessToken{token: token.AccessToken, expiry: now().Add(time.Duration(token.ExpiresIn) * time.Second)}
+	return token.AccessToken, nil
+}
+
+// CorrelationID identifies the incident of a recurring condition
+func CorrelationID(notification publisher.Notification) string {
+	return publisher.Truncate(correlationDisplay+":"+publisher.Fingerprint(notification), maxCorrelationID)
+}
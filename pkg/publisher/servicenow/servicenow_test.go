@@ -0,0 +1,177 @@
+package servicenow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func backOffEvent(name string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: name},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Type:           corev1.EventTypeWarning,
+		Count:          3,
+	}
+}
+
+// fakeInstance keeps incidents in memory and answers the Table API
+type fakeInstance struct {
+	mu        sync.Mutex
+	incidents []map[string]string
+	notes     map[string][]string
+	tokens    int
+	// authorization is the header expected on Table API requests
+	authorization string
+}
+
+func newFakeInstance(t *testing.T, authorization string) (*fakeInstance, *httptest.Server) {
+	f := &fakeInstance{notes: map[string][]string{}, authorization: authorization}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /oauth_token.do", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.FormValue("client_id") != "notifier" || r.FormValue("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.FormValue("grant_type") == "password" && (r.FormValue("username") != "integration" || r.FormValue("password") != "password") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.tokens++
+		_, _ = w.Write([]byte(`{"access_token":"access","token_type":"Bearer","expires_in":1800}`))
+	})
+	mux.HandleFunc("/api/now/table/incident", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.Header.Get("Authorization") != f.authorization {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodGet {
+			found := []map[string]string{}
+			for _, incident := range f.incidents {
+				if strings.Contains(r.URL.Query().Get("sysparm_query"), "correlation_id="+incident["correlation_id"]+"^") {
+					found = append(found, map[string]string{"sys_id": incident["sys_id"], "number": incident["number"]})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"result": found})
+			return
+		}
+		var incident map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&incident); err != nil {
+			t.Error(err)
+		}
+		incident["sys_id"] = "sys" + string(rune('1'+len(f.incidents)))
+		incident["number"] = "INC000" + string(rune('1'+len(f.incidents)))
+		f.incidents = append(f.incidents, incident)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": incident})
+	})
+	mux.HandleFunc("PATCH /api/now/table/incident/{id}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		var update map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&update)
+		f.notes[r.PathValue("id")] = append(f.notes[r.PathValue("id")], update["work_notes"])
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func TestServiceNowPublisherSend(t *testing.T) {
+	f, server := newFakeInstance(t, "Basic aW50ZWdyYXRpb246cGFzc3dvcmQ=")
+	p := NewServiceNowPublisher(Config{
+		URL:             server.URL + "/",
+		Username:        "integration",
+		Password:        "password",
+		AssignmentGroup: "Platform",
+		Category:        "Software",
+		Severities: []publisher.Rule[Severity]{
+			{Reason: "OOMKilling", Value: Severity{Urgency: High, Impact: High}},
+			{Type: corev1.EventTypeWarning, Value: Severity{Urgency: Medium, Impact: Medium}},
+		},
+	}, nil)
+
+	ctx := context.Background()
+	for _, n := range []publisher.Notification{
+		{Message: "cart-0 is crash looping", Event: backOffEvent("cart-0"), Owner: "StatefulSet/cart"},
+		{Message: "cart-1 is crash looping", Event: backOffEvent("cart-1"), Owner: "StatefulSet/cart"},
+		{Message: "3 events"},
+	} {
+		if err := p.Send(ctx, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(f.incidents) != 1 {
+		t.Fatalf("expected a single incident, got %v", f.incidents)
+	}
+	incident := f.incidents[0]
+	if incident["short_description"] != "BackOff: Pod shop/cart-0" || incident["description"] != "cart-0 is crash looping" {
+		t.Errorf("unexpected incident %v", incident)
+	}
+	if incident["assignment_group"] != "Platform" || incident["category"] != "Software" || incident["urgency"] != "2" || incident["impact"] != "2" {
+		t.Errorf("unexpected assignment or severity %v", incident)
+	}
+	if _, ok := incident["caller_id"]; ok {
+		t.Errorf("expected unset fields to be omitted, got %v", incident)
+	}
+	if !strings.HasPrefix(incident["correlation_id"], correlationDisplay+":") {
+		t.Errorf("unexpected correlation id %q", incident["correlation_id"])
+	}
+	if notes := f.notes["sys1"]; len(notes) != 1 || !strings.Contains(notes[0], "cart-1 is crash looping") {
+		t.Errorf("expected the repetition as a work note, got %v", notes)
+	}
+
+	oom := backOffEvent("cart-0")
+	oom.Reason = "OOMKilling"
+	if err := p.Send(ctx, publisher.Notification{Message: "out of memory", Event: oom, Owner: "StatefulSet/cart"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.incidents) != 2 || f.incidents[1]["urgency"] != "1" || f.incidents[1]["impact"] != "1" {
+		t.Errorf("expected a high urgency incident for another reason, got %v", f.incidents)
+	}
+}
+
+func TestServiceNowPublisherOAuth(t *testing.T) {
+	for name, config := range map[string]Config{
+		"client credentials": {OAuth: &OAuth{ClientID: "notifier", ClientSecret: "secret"}},
+		"password":           {Username: "integration", Password: "password", OAuth: &OAuth{ClientID: "notifier", ClientSecret: "secret"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			f, server := newFakeInstance(t, "Bearer access")
+			config.URL = server.URL
+
+			// publishers are created on every reconcile, the cache keeps the token across them
+			tokens := &TokenCache{}
+			normal := backOffEvent("cart-0")
+			normal.Type = corev1.EventTypeNormal
+			for range 2 {
+				p := NewServiceNowPublisher(config, tokens)
+				if err := p.Send(context.Background(), publisher.Notification{Message: "cart-0 pulled", Event: normal}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if f.tokens != 1 {
+				t.Errorf("expected the access token to be reused, %d were issued", f.tokens)
+			}
+			if len(f.incidents) != 1 || f.incidents[0]["urgency"] != "3" || f.incidents[0]["impact"] != "3" {
+				t.Errorf("expected a low urgency incident, got %v", f.incidents)
+			}
+		})
+	}
+}
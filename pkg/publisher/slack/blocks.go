@@ -0,0 +1,190 @@
+package slack
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+// Block Kit limits, see https://api.slack.com/reference/block-kit/blocks
+const (
+	maxBlocks         = 50
+	maxHeaderText     = 150
+	maxSectionText    = 3000
+	maxFieldText      = 2000
+	maxContextText    = 2000
+	maxActionElements = 25
+	maxButtonText     = 75
+	maxButtonURL      = 3000
+	maxFallbackText   = 40000
+)
+
+// Block is a Slack Block Kit layout block
+type Block struct {
+	Type     string    `json:"type"`
+	Text     *Text     `json:"text,omitempty"`
+	Fields   []Text    `json:"fields,omitempty"`
+	Elements []Element `json:"elements,omitempty"`
+}
+
+// Text is a Block Kit text object
+type Text struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Emoji bool   `json:"emoji,omitempty"`
+}
+
+// Element is a Block Kit context or actions element
+type Element struct {
+	Type string `json:"type"`
+	Text any    `json:"text,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Link is a button added to event messages
+type Link struct {
+	Text string
+	url  *template.Template
+}
+
+// NewLink parses the URL of a button, a text/template evaluated against publisher.TemplateData.
+// Event values should be escaped with the urlquery function, e.g.
+// https://grafana.example.com/d/pods?var-pod={{ .InvolvedObject.Name | urlquery }}
+func NewLink(text, url string) (Link, error) {
+	tmpl, err := template.New("url").Option("missingkey=zero").Parse(url)
+	if err != nil {
+		return Link{}, fmt.Errorf("invalid URL template of link %q: %w", text, err)
+	}
+	return Link{Text: text, url: tmpl}, nil
+}
+
+// buildBlocks lays out the notification as Block Kit blocks. Notifications
+// without an event, such as digests, are sent as plain text only.
+func buildBlocks(notification publisher.Notification, buttons []Element, footer string) []Block {
+	event := notification.Event
+	if event == nil {
+		return nil
+	}
+
+	blocks := []Block{
+		{
+			Type: "header",
+			Text: &Text{
+				Type:  "plain_text",
+				Text:  publisher.Truncate(fmt.Sprintf("%s %s: %s %s", severityEmoji(event.Type), event.Reason, event.InvolvedObject.Kind, event.InvolvedObject.Name), maxHeaderText),
+				Emoji: true,
+			},
+		},
+		{
+			Type: "section",
+			Text: &Text{Type: "mrkdwn", Text: publisher.Truncate(escape(event.Message), maxSectionText)},
+			Fields: []Text{
+				field("Namespace", event.Namespace),
+				field("Kind", event.InvolvedObject.Kind),
+				field("Name", event.InvolvedObject.Name),
+				field("Reason", event.Reason),
+			},
+		},
+	}
+	if event.Message == "" {
+		blocks[1].Text = nil
+	}
+
+	details := []string{}
+	if !event.FirstTimestamp.IsZero() {
+		details = append(details, "First seen "+event.FirstTimestamp.UTC().Format(time.RFC3339))
+	}
+	if !event.LastTimestamp.IsZero() {
+		details = append(details, "Last seen "+event.LastTimestamp.UTC().Format(time.RFC3339))
+	}
+	if event.Count > 1 {
+		details = append(details, fmt.Sprintf("Count %d", event.Count))
+	}
+	if notification.ClusterName != "" {
+		details = append(details, "Cluster *"+escape(notification.ClusterName)+"*")
+	}
+	if footer != "" {
+		details = append(details, footer)
+	}
+	if len(details) > 0 {
+		blocks = append(blocks, Block{
+			Type:     "context",
+			Elements: []Element{{Type: "mrkdwn", Text: publisher.Truncate(strings.Join(details, " | "), maxContextText)}},
+		})
+	}
+
+	if len(buttons) > 0 {
+		blocks = append(blocks, Block{Type: "actions", Elements: buttons})
+	}
+
+	if len(blocks) > maxBlocks {
+		blocks = blocks[:maxBlocks]
+	}
+	return blocks
+}
+
+// buildButtons renders the links of an event notification. Links that fail to render are
+// left out and reported to onError, so that the message is still sent.
+func buildButtons(notification publisher.Notification, links []Link, onError func(error)) []Element {
+	if notification.Event == nil {
+		return nil
+	}
+
+	buttons := []Element{}
+	var errs []error
+	data := publisher.NewTemplateData(notification)
+	for _, link := range links {
+		if len(buttons) == maxActionElements {
+			break
+		}
+
+		var url strings.Builder
+		if err := link.url.Execute(&url, data); err != nil {
+			errs = append(errs, fmt.Errorf("failed to render URL of link %q: %w", link.Text, err))
+			continue
+		}
+		if url.Len() > maxButtonURL {
+			errs = append(errs, fmt.Errorf("URL of link %q is longer than %d characters", link.Text, maxButtonURL))
+			continue
+		}
+
+		buttons = append(buttons, Element{
+			Type: "button",
+			Text: Text{Type: "plain_text", Text: publisher.Truncate(link.Text, maxButtonText), Emoji: true},
+			URL:  url.String(),
+		})
+	}
+	if err := errors.Join(errs...); err != nil && onError != nil {
+		onError(err)
+	}
+	return buttons
+}
+
+func field(name, value string) Text {
+	if value == "" {
+		value = "-"
+	}
+	return Text{Type: "mrkdwn", Text: publisher.Truncate(fmt.Sprintf("*%s*\n%s", name, escape(value)), maxFieldText)}
+}
+
+func severityEmoji(eventType string) string {
+	switch eventType {
+	case corev1.EventTypeWarning:
+		return ":warning:"
+	case corev1.EventTypeNormal:
+		return ":information_source:"
+	default:
+		return ":grey_question:"
+	}
+}
+
+// escape encodes the control characters of Slack mrkdwn
+func escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
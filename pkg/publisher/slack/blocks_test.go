@@ -0,0 +1,112 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func TestSlackPublisherBlocks(t *testing.T) {
+	var payload Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	seen := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	event := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "checkout 7f9c"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container <app>",
+		Type:           corev1.EventTypeWarning,
+		FirstTimestamp: metav1.NewTime(seen),
+		LastTimestamp:  metav1.NewTime(seen.Add(time.Minute)),
+		Count:          3,
+	}
+
+	if _, err := NewLink("Broken", "{{ .Nope"); err == nil {
+		t.Error("expected a link template that does not parse to be rejected")
+	}
+	logs, err := NewLink("Logs", "https://grafana.example.com/explore?cluster={{ .ClusterName }}&pod={{ .InvolvedObject.Name | urlquery }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	long, err := NewLink("Long", "https://example.com/{{ .Message }}"+strings.Repeat("x", maxButtonURL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	failing, err := NewLink("Failing", "https://example.com/{{ index .Message 1000 }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var linkErrs []error
+	p := NewSlackPublisher(server.URL, []Link{logs, long, failing}, func(err error) { linkErrs = append(linkErrs, err) })
+	// the message is posted without the broken links, failing the send would post it again on retry
+	if err := p.Send(context.Background(), publisher.Notification{Message: "fallback", Event: event, ClusterName: "prod-eu"}); err != nil {
+		t.Fatalf("expected the message to be posted despite broken links, got %v", err)
+	}
+	if len(linkErrs) != 1 || !strings.Contains(linkErrs[0].Error(), `link "Long"`) || !strings.Contains(linkErrs[0].Error(), `link "Failing"`) {
+		t.Errorf("expected the broken links to be reported, got %v", linkErrs)
+	}
+
+	if payload.Text != "fallback" {
+		t.Errorf("expected the fallback text to be kept, got %q", payload.Text)
+	}
+	if len(payload.Blocks) != 4 {
+		t.Fatalf("expected header, section, context and actions blocks, got %+v", payload.Blocks)
+	}
+
+	header, section, details, actions := payload.Blocks[0], payload.Blocks[1], payload.Blocks[2], payload.Blocks[3]
+	if header.Text.Text != ":warning: BackOff: Pod checkout 7f9c" {
+		t.Errorf("unexpected header %q", header.Text.Text)
+	}
+	if section.Text.Text != "Back-off restarting failed container &lt;app&gt;" || len(section.Fields) != 4 {
+		t.Errorf("unexpected section %+v", section)
+	}
+	contextText := details.Elements[0].Text.(string)
+	for _, want := range []string{"First seen 2025-03-03T09:00:00Z", "Last seen 2025-03-03T09:01:00Z", "Count 3", "Cluster *prod-eu*"} {
+		if !strings.Contains(contextText, want) {
+			t.Errorf("context %q missing %q", contextText, want)
+		}
+	}
+	if len(actions.Elements) != 1 || actions.Elements[0].URL != "https://grafana.example.com/explore?cluster=prod-eu&pod=checkout+7f9c" {
+		t.Errorf("unexpected actions %+v", actions.Elements)
+	}
+}
+
+func TestBuildBlocksLimits(t *testing.T) {
+	event := &corev1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: strings.Repeat("p", 300)},
+		Message:        strings.Repeat("m", 5000),
+	}
+
+	blocks := buildBlocks(publisher.Notification{Event: event}, nil, "")
+
+	if n := utf8.RuneCountInString(blocks[0].Text.Text); n != maxHeaderText {
+		t.Errorf("expected header truncated to %d characters, got %d", maxHeaderText, n)
+	}
+	if n := utf8.RuneCountInString(blocks[1].Text.Text); n != maxSectionText {
+		t.Errorf("expected section truncated to %d characters, got %d", maxSectionText, n)
+	}
+	if !strings.HasSuffix(blocks[1].Text.Text, "…") {
+		t.Error("expected truncated text to end with an ellipsis")
+	}
+
+	if blocks := buildBlocks(publisher.Notification{Message: "digest"}, nil, ""); blocks != nil {
+		t.Errorf("expected no blocks without an event, got %+v", blocks)
+	}
+}
@@ -7,22 +7,38 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/example/notifier/pkg/publisher"
 )
 
 type Payload struct {
-	Text string `json:"text"`
+	// Text is the notification fallback when blocks are set
+	Text   string  `json:"text"`
+	Blocks []Block `json:"blocks,omitempty"`
 }
 
 type SlackPublisher struct {
 	WebhookURL string
+	Links      []Link
+	// OnLinkError is called with links that fail to render, the message is posted without them
+	OnLinkError func(error)
+}
+
+func NewSlackPublisher(webhookURL string, links []Link, onLinkError func(error)) *SlackPublisher {
+	return &SlackPublisher{WebhookURL: webhookURL, Links: links, OnLinkError: onLinkError}
 }
 
-func NewSlackPublisher(webhookURL string) *SlackPublisher {
-	return &SlackPublisher{WebhookURL: webhookURL}
+func (s *SlackPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	payload := Payload{
+		Text:   publisher.Truncate(notification.Message, maxFallbackText),
+		Blocks: buildBlocks(notification, buildButtons(notification, s.Links, s.OnLinkError), ""),
+	}
+	return PostWebhook(ctx, s.WebhookURL, payload)
 }
 
-func (s *SlackPublisher) Send(ctx context.Context, message string) error {
-	payload := Payload{Text: message}
+// PostWebhook posts payload to a Slack compatible incoming webhook, such as the
+// ones of Mattermost and Rocket.Chat, which answer 200 OK on success
+func PostWebhook(ctx context.Context, webhookURL string, payload any) error {
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
 		return err
@@ -30,7 +46,7 @@ func (s *SlackPublisher) Send(ctx context.Context, message string) error {
 
 	req, err := http.NewRequestWithContext(ctx,
 		http.MethodPost,
-		s.WebhookURL,
+		webhookURL,
 		bytes.NewBuffer(jsonPayload),
 	)
 	if err != nil {
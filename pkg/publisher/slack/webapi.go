@@ -0,0 +1,200 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+const DefaultAPIURL = "https://slack.com/api"
+
+// Thread is the Slack message that repeated events for one object are replied under
+type Thread struct {
+	Channel string
+	TS      string
+	Count   int
+}
+
+// ThreadStore keeps track of the thread posted for each involved object
+type ThreadStore interface {
+	Get(key string) (Thread, bool)
+	Put(key string, thread Thread)
+	Delete(key string)
+}
+
+// APIError is returned when the Slack Web API answers with ok=false
+type APIError struct {
+	Method string
+	Code   string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("slack %s failed: %s", e.Method, e.Code)
+}
+
+type apiMessage struct {
+	Channel  string  `json:"channel"`
+	Text     string  `json:"text"`
+	Blocks   []Block `json:"blocks,omitempty"`
+	TS       string  `json:"ts,omitempty"`
+	ThreadTS string  `json:"thread_ts,omitempty"`
+}
+
+type apiResponse struct {
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	TS      string `json:"ts,omitempty"`
+}
+
+// WebAPIPublisher posts through chat.postMessage using a bot token. The first
+// event for an object starts a thread, later events are posted as replies and
+// the parent message is updated with a running count and the latest state.
+type WebAPIPublisher struct {
+	APIURL  string
+	Token   string
+	Channel string
+	Threads ThreadStore
+	Links   []Link
+	// OnLinkError is called with links that fail to render, the message is posted without them
+	OnLinkError func(error)
+}
+
+func NewWebAPIPublisher(token, channel string, threads ThreadStore, links []Link, onLinkError func(error)) *WebAPIPublisher {
+	return &WebAPIPublisher{
+		APIURL:      DefaultAPIURL,
+		Token:       token,
+		Channel:     channel,
+		Threads:     threads,
+		Links:       links,
+		OnLinkError: onLinkError,
+	}
+}
+
+func (s *WebAPIPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	return s.send(ctx, notification, buildButtons(notification, s.Links, s.OnLinkError))
+}
+
+func (s *WebAPIPublisher) send(ctx context.Context, notification publisher.Notification, buttons []Element) error {
+	if notification.Event == nil {
+		_, err := s.call(ctx, "chat.postMessage", s.message(s.Channel, notification, "", buttons))
+		return err
+	}
+
+	key := publisher.ObjectKey(notification.Event)
+	thread, ok := s.Threads.Get(key)
+	if ok {
+		thread.Count++
+		update := s.message(thread.Channel, notification, threadSummary(notification, thread.Count), buttons)
+		update.TS = thread.TS
+		_, err := s.call(ctx, "chat.update", update)
+
+		var apiErr *APIError
+		switch {
+		case errors.As(err, &apiErr) && apiErr.Code == "message_not_found":
+			// the parent message was deleted, start a new thread
+			s.Threads.Delete(key)
+			ok = false
+		case err != nil:
+			return err
+		default:
+			s.Threads.Put(key, thread)
+		}
+	}
+
+	if !ok {
+		resp, err := s.call(ctx, "chat.postMessage", s.message(s.Channel, notification, "", buttons))
+		if err != nil {
+			return err
+		}
+		s.Threads.Put(key, Thread{Channel: resp.Channel, TS: resp.TS, Count: 1})
+		return nil
+	}
+
+	reply := s.message(thread.Channel, notification, "", buttons)
+	reply.ThreadTS = thread.TS
+	_, err := s.call(ctx, "chat.postMessage", reply)
+	return err
+}
+
+// message builds the Block Kit message, with summary appended to the fallback text and context
+func (s *WebAPIPublisher) message(channel string, notification publisher.Notification, summary string, buttons []Element) apiMessage {
+	text := notification.Message
+	if summary != "" {
+		text += "\n_" + summary + "_"
+	}
+
+	return apiMessage{
+		Channel: channel,
+		Text:    publisher.Truncate(text, maxFallbackText),
+		Blocks:  buildBlocks(notification, buttons, summary),
+	}
+}
+
+func (s *WebAPIPublisher) call(ctx context.Context, method string, message apiMessage) (*apiResponse, error) {
+	jsonPayload, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx,
+		http.MethodPost,
+		strings.TrimSuffix(s.APIURL, "/")+"/"+method,
+		bytes.NewBuffer(jsonPayload),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			fmt.Printf("failed to close response body %s", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to call slack %s: %s (status: %d)", method, string(body), resp.StatusCode)
+	}
+
+	var result apiResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode slack %s response: %w", method, err)
+	}
+	if !result.OK {
+		return nil, &APIError{Method: method, Code: result.Error}
+	}
+
+	return &result, nil
+}
+
+// threadSummary describes the thread on its parent message: how many events were seen and the latest one
+func threadSummary(notification publisher.Notification, count int) string {
+	event := notification.Event
+	seen := publisher.EventTime(event, time.Now())
+
+	return fmt.Sprintf("%d events so far, latest *%s* at %s",
+		count,
+		event.Reason,
+		seen.UTC().Format(time.RFC3339),
+	)
+}
@@ -0,0 +1,165 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+type memoryThreads map[string]Thread
+
+func (m memoryThreads) Get(key string) (Thread, bool) {
+	thread, ok := m[key]
+	return thread, ok
+}
+
+func (m memoryThreads) Put(key string, thread Thread) { m[key] = thread }
+
+func (m memoryThreads) Delete(key string) { delete(m, key) }
+
+type call struct {
+	method  string
+	message apiMessage
+}
+
+// fakeSlack records Web API calls and answers them like Slack would
+func fakeSlack(t *testing.T, respond func(method string, message apiMessage) apiResponse) (*httptest.Server, *[]call) {
+	t.Helper()
+
+	var (
+		mu    sync.Mutex
+		calls []call
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer xoxb-test" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+
+		var message apiMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		method := strings.TrimPrefix(r.URL.Path, "/")
+
+		mu.Lock()
+		calls = append(calls, call{method: method, message: message})
+		mu.Unlock()
+
+		_ = json.NewEncoder(w).Encode(respond(method, message))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func podEvent(reason string) *corev1.Event {
+	return &corev1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web-0"},
+		Reason:         reason,
+	}
+}
+
+func TestWebAPIPublisherThreads(t *testing.T) {
+	server, calls := fakeSlack(t, func(method string, message apiMessage) apiResponse {
+		return apiResponse{OK: true, Channel: "C123", TS: "1700000000.000100"}
+	})
+
+	threads := memoryThreads{}
+	p := NewWebAPIPublisher("xoxb-test", "C123", threads, nil, nil)
+	p.APIURL = server.URL
+
+	ctx := context.Background()
+	if err := p.Send(ctx, publisher.Notification{Message: "first", Event: podEvent("BackOff")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(ctx, publisher.Notification{Message: "second", Event: podEvent("Failed")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*calls) != 3 {
+		t.Fatalf("expected 3 calls, got %+v", *calls)
+	}
+
+	first, update, reply := (*calls)[0], (*calls)[1], (*calls)[2]
+	if first.method != "chat.postMessage" || first.message.ThreadTS != "" || first.message.Text != "first" {
+		t.Errorf("unexpected parent message %+v", first)
+	}
+	if update.method != "chat.update" || update.message.TS != "1700000000.000100" ||
+		!strings.Contains(update.message.Text, "second") || !strings.Contains(update.message.Text, "2 events so far") {
+		t.Errorf("unexpected parent update %+v", update)
+	}
+	if reply.method != "chat.postMessage" || reply.message.ThreadTS != "1700000000.000100" || reply.message.Text != "second" {
+		t.Errorf("unexpected thread reply %+v", reply)
+	}
+
+	if thread := threads["default/Pod/web-0"]; thread.Count != 2 || thread.TS != "1700000000.000100" {
+		t.Errorf("unexpected stored thread %+v", thread)
+	}
+}
+
+func TestWebAPIPublisherRestartsDeletedThread(t *testing.T) {
+	server, calls := fakeSlack(t, func(method string, message apiMessage) apiResponse {
+		if method == "chat.update" {
+			return apiResponse{OK: false, Error: "message_not_found"}
+		}
+		return apiResponse{OK: true, Channel: "C123", TS: "1700000001.000200"}
+	})
+
+	threads := memoryThreads{"default/Pod/web-0": {Channel: "C123", TS: "1600000000.000100", Count: 4}}
+	p := NewWebAPIPublisher("xoxb-test", "C123", threads, nil, nil)
+	p.APIURL = server.URL
+
+	if err := p.Send(context.Background(), publisher.Notification{Message: "again", Event: podEvent("BackOff")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*calls) != 2 || (*calls)[1].message.ThreadTS != "" {
+		t.Errorf("expected a new parent message after the update failed, got %+v", *calls)
+	}
+	if thread := threads["default/Pod/web-0"]; thread.Count != 1 || thread.TS != "1700000001.000200" {
+		t.Errorf("unexpected stored thread %+v", thread)
+	}
+}
+
+func TestWebAPIPublisherError(t *testing.T) {
+	server, _ := fakeSlack(t, func(method string, message apiMessage) apiResponse {
+		return apiResponse{OK: false, Error: "channel_not_found"}
+	})
+
+	p := NewWebAPIPublisher("xoxb-test", "C404", memoryThreads{}, nil, nil)
+	p.APIURL = server.URL
+
+	err := p.Send(context.Background(), publisher.Notification{Message: "digest"})
+	if err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("expected channel_not_found error, got %v", err)
+	}
+}
+
+func TestWebAPIPublisherBrokenLink(t *testing.T) {
+	server, calls := fakeSlack(t, func(method string, message apiMessage) apiResponse {
+		return apiResponse{OK: true, Channel: "C123", TS: "1700000000.000100"}
+	})
+
+	failing, err := NewLink("Failing", "https://example.com/{{ index .Reason 1000 }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var linkErrs []error
+	p := NewWebAPIPublisher("xoxb-test", "C123", memoryThreads{}, []Link{failing}, func(err error) { linkErrs = append(linkErrs, err) })
+	p.APIURL = server.URL
+
+	if err := p.Send(context.Background(), publisher.Notification{Message: "first", Event: podEvent("BackOff")}); err != nil {
+		t.Fatalf("expected the message to be posted despite the broken link, got %v", err)
+	}
+	if len(*calls) != 1 || len(linkErrs) != 1 {
+		t.Errorf("expected the message posted once and the link reported, got %+v and %v", *calls, linkErrs)
+	}
+}
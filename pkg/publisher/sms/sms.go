@@ -0,0 +1,265 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/time/rate"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+const (
+	DefaultBaseURL     = "https://api.twilio.com"
+	DefaultMaxMessages = 5
+	DefaultPeriod      = time.Hour
+)
+
+// Lengths of a single message segment, in GSM-7 characters or UTF-16 code units
+// once any character is outside the GSM-7 alphabet
+const (
+	gsm7Length = 160
+	ucs2Length = 70
+	ellipsis   = "..."
+)
+
+const (
+	// gsm7Basic is the GSM 03.38 default alphabet, counting one character each
+	gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+	// gsm7Extension characters are escaped, counting two characters each
+	gsm7Extension = "^{}\\[~]|€\f"
+)
+
+var (
+	now = time.Now
+
+	phoneNumberPattern = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+)
+
+// Config defines the account messages are sent from and who receives them
+type Config struct {
+	// BaseURL of a Twilio compatible API, the account messages path is appended to it
+	BaseURL    string
+	AccountSID string
+	AuthToken  string
+	// From is the sending number, unless MessagingServiceSID is set
+	From                string
+	MessagingServiceSID string
+	// Recipients are E.164 phone numbers
+	Recipients []string
+	// MaxMessages are sent to each recipient per Period, further ones are dropped
+	MaxMessages int
+	Period      time.Duration
+}
+
+// SMSPublisher texts a condensed rendering of every event to each recipient.
+// Messages over the rate limit of a recipient are dropped, and the number dropped
+// is prepended to the next message they receive.
+type SMSPublisher struct {
+	config   Config
+	endpoint string
+	limit    rate.Limit
+	limiters *Limiters
+}
+
+// Limiters keeps the rate limit of each recipient by phone number. Shared by all
+// publishers, a number is limited once however many notifiers text it, and keeps
+// its limit when the recipients or credentials of a notifier change. A number
+// limited differently by several notifiers follows the settings of the last one
+// to text it. The zero value is ready to use.
+type Limiters struct {
+	mu         sync.Mutex
+	recipients map[string]*recipient
+}
+
+type recipient struct {
+	limiter    *rate.Limiter
+	suppressed int
+}
+
+// NewSMSPublisher creates a publisher taking the rate limits of recipients from
+// limiters. A nil Limiters limits recipients for the lifetime of the publisher only.
+func NewSMSPublisher(config Config, limiters *Limiters) (*SMSPublisher, error) {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultBaseURL
+	}
+	if config.MaxMessages <= 0 {
+		config.MaxMessages = DefaultMaxMessages
+	}
+	if config.Period <= 0 {
+		config.Period = DefaultPeriod
+	}
+	if config.AccountSID == "" {
+		return nil, errors.New("an sms account SID is required")
+	}
+	if (config.From == "") == (config.MessagingServiceSID == "") {
+		return nil, errors.New("either an sms sender number or a messaging service SID is required")
+	}
+	if len(config.Recipients) == 0 {
+		return nil, errors.New("at least one sms recipient is required")
+	}
+	for _, number := range config.Recipients {
+		if !phoneNumberPattern.MatchString(number) {
+			return nil, fmt.Errorf("invalid sms recipient %q, expected an E.164 number such as +15551234567", number)
+		}
+	}
+	if limiters == nil {
+		limiters = &Limiters{}
+	}
+
+	return &SMSPublisher{
+		config:   config,
+		endpoint: strings.TrimSuffix(config.BaseURL, "/") + "/2010-04-01/Accounts/" + url.PathEscape(config.AccountSID) + "/Messages.json",
+		limit:    rate.Every(config.Period / time.Duration(config.MaxMessages)),
+		limiters: limiters,
+	}, nil
+}
+
+func (s *SMSPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	text := Condense(notification)
+
+	var errs []error
+	for _, number := range s.config.Recipients {
+		body, suppressed, ok := s.limiters.allow(number, s.limit, s.config.MaxMessages, text)
+		if !ok {
+			continue
+		}
+		if err := s.send(ctx, number, body); err != nil {
+			// the dropped messages are still to be counted in the next message
+			s.limiters.restore(number, suppressed)
+			errs = append(errs, fmt.Errorf("failed to send sms to %s: %w", number, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// allow takes a message from the rate limit of number, returning the body to send
+// and the number of dropped messages it counts, or false if the message is dropped
+func (l *Limiters) allow(number string, limit rate.Limit, burst int, text string) (string, int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r, ok := l.recipients[number]
+	switch {
+	case !ok:
+		if l.recipients == nil {
+			l.recipients = make(map[string]*recipient)
+		}
+		r = &recipient{limiter: rate.NewLimiter(limit, burst)}
+		l.recipients[number] = r
+	case r.limiter.Limit() != limit || r.limiter.Burst() != burst:
+		r.limiter.SetLimitAt(now(), limit)
+		r.limiter.SetBurstAt(now(), burst)
+	}
+
+	if !r.limiter.AllowN(now(), 1) {
+		r.suppressed++
+		return "", 0, false
+	}
+	suppressed := r.suppressed
+	if suppressed > 0 {
+		text = fmt.Sprintf("(+%d dropped) %s", suppressed, text)
+		r.suppressed = 0
+	}
+	return Fit(text), suppressed, true
+}
+
+// restore counts the dropped messages of a message that failed to send back to number
+func (l *Limiters) restore(number string, suppressed int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r, ok := l.recipients[number]; ok {
+		r.suppressed += suppressed
+	}
+}
+
+func (s *SMSPublisher) send(ctx context.Context, number, body string) error {
+	form := url.Values{"To": {number}, "Body": {body}}
+	if s.config.MessagingServiceSID != "" {
+		form.Set("MessagingServiceSid", s.config.MessagingServiceSID)
+	} else {
+		form.Set("From", s.config.From)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(s.config.AccountSID, s.config.AuthToken)
+	_, err = publisher.Do(req)
+	return err
+}
+
+// Condense renders the notification on a single line, leading with what happened
+// to which object so that it survives being cut to one segment
+func Condense(notification publisher.Notification) string {
+	text := notification.Message
+	if event := notification.Event; event != nil {
+		namespace := event.InvolvedObject.Namespace
+		if namespace == "" {
+			namespace = event.Namespace
+		}
+		text = fmt.Sprintf("%s %s %s/%s: %s", event.Reason, event.InvolvedObject.Kind, namespace, event.InvolvedObject.Name, event.Message)
+	}
+	if notification.ClusterName != "" {
+		text = "[" + notification.ClusterName + "] " + text
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Fit cuts text to a single segment: 160 GSM-7 characters, or 70 UTF-16 code
+// units if text has characters outside the GSM-7 alphabet
+func Fit(text string) string {
+	length, limit := gsm7Units, gsm7Length
+	for _, r := range text {
+		if gsm7Units(r) == 0 {
+			length, limit = utf16Units, ucs2Length
+			break
+		}
+	}
+
+	total := 0
+	for _, r := range text {
+		total += length(r)
+	}
+	if total <= limit {
+		return text
+	}
+
+	budget := limit - len(ellipsis)
+	var b strings.Builder
+	for _, r := range text {
+		if budget -= length(r); budget < 0 {
+			break
+		}
+		b.WriteRune(r)
+	}
+	return strings.TrimRight(b.String(), " ") + ellipsis
+}
+
+// gsm7Units is the number of GSM-7 characters r is encoded in, zero if r is not in the alphabet
+func gsm7Units(r rune) int {
+	switch {
+	case strings.ContainsRune(gsm7Basic, r):
+		return 1
+	case strings.ContainsRune(gsm7Extension, r):
+		return 2
+	default:
+		return 0
+	}
+}
+
+func utf16Units(r rune) int {
+	return utf16.RuneLen(r)
+}
@@ -0,0 +1,252 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func oomEvent() *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "payments"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "ledger-0"},
+		Reason:         "OOMKilling",
+		Message:        "Memory cgroup out of memory:\n  Killed process 4242 (java)",
+		Type:           corev1.EventTypeWarning,
+	}
+}
+
+type message struct {
+	to, from, service, body string
+}
+
+func newFakeTwilio(t *testing.T) (*[]message, *httptest.Server) {
+	var mu sync.Mutex
+	var messages []message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if user, password, ok := r.BasicAuth(); !ok || user != "AC123" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.FormValue("To") == "+15550000000" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":21211,"message":"Invalid 'To' Phone Number"}`))
+			return
+		}
+		messages = append(messages, message{r.FormValue("To"), r.FormValue("From"), r.FormValue("MessagingServiceSid"), r.FormValue("Body")})
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"SM1","status":"queued"}`))
+	}))
+	t.Cleanup(server.Close)
+	return &messages, server
+}
+
+func TestSMSPublisherSend(t *testing.T) {
+	messages, server := newFakeTwilio(t)
+	p, err := NewSMSPublisher(Config{
+		BaseURL:    server.URL + "/",
+		AccountSID: "AC123",
+		AuthToken:  "secret",
+		From:       "+15557654321",
+		Recipients: []string{"+15551234567", "+4915112345678"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notification := publisher.Notification{Message: "ignored for events", Event: oomEvent(), ClusterName: "prod"}
+	if err := p.Send(context.Background(), notification); err != nil {
+		t.Fatal(err)
+	}
+	if len(*messages) != 2 {
+		t.Fatalf("expected a message per recipient, got %v", *messages)
+	}
+	want := "[prod] OOMKilling Pod payments/ledger-0: Memory cgroup out of memory: Killed process 4242 (java)"
+	for i, to := range []string{"+15551234567", "+4915112345678"} {
+		if m := (*messages)[i]; m.to != to || m.from != "+15557654321" || m.body != want {
+			t.Errorf("unexpected message %+v", m)
+		}
+	}
+
+	failing, err := NewSMSPublisher(Config{BaseURL: server.URL, AccountSID: "AC123", AuthToken: "secret", MessagingServiceSID: "MG1", Recipients: []string{"+15550000000", "+15551234567"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := failing.Send(context.Background(), notification); err == nil || !strings.Contains(err.Error(), "+15550000000") {
+		t.Errorf("expected the failed recipient to be reported, got %v", err)
+	}
+	if m := (*messages)[2]; m.to != "+15551234567" || m.service != "MG1" || m.from != "" {
+		t.Errorf("expected the other recipient to be sent to through the messaging service, got %+v", m)
+	}
+}
+
+func TestSMSPublisherRateLimit(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	messages, server := newFakeTwilio(t)
+	p, err := NewSMSPublisher(Config{
+		BaseURL:     server.URL,
+		AccountSID:  "AC123",
+		AuthToken:   "secret",
+		From:        "+15557654321",
+		Recipients:  []string{"+15551234567"},
+		MaxMessages: 2,
+		Period:      time.Hour,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 5 {
+		if err := p.Send(context.Background(), publisher.Notification{Message: "3 events"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(*messages) != 2 {
+		t.Fatalf("expected messages over the limit to be dropped, got %v", *messages)
+	}
+
+	clock = clock.Add(30 * time.Minute)
+	if err := p.Send(context.Background(), publisher.Notification{Message: "3 events"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*messages) != 3 || (*messages)[2].body != "(+3 dropped) 3 events" {
+		t.Errorf("expected the dropped messages to be counted once the limit allows, got %v", *messages)
+	}
+}
+
+func TestSMSPublisherRateLimitSendFailure(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	var bodies []string
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		bodies = append(bodies, r.FormValue("Body"))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	p, err := NewSMSPublisher(Config{
+		BaseURL:     server.URL,
+		AccountSID:  "AC123",
+		From:        "+15557654321",
+		Recipients:  []string{"+15551234567"},
+		MaxMessages: 1,
+		Period:      time.Hour,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		if err := p.Send(context.Background(), publisher.Notification{Message: "3 events"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clock = clock.Add(time.Hour)
+	fail = true
+	if err := p.Send(context.Background(), publisher.Notification{Message: "3 events"}); err == nil {
+		t.Fatal("expected the failed message to be reported")
+	}
+
+	clock = clock.Add(time.Hour)
+	fail = false
+	if err := p.Send(context.Background(), publisher.Notification{Message: "3 events"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 2 || bodies[1] != "(+2 dropped) 3 events" {
+		t.Errorf("expected the dropped messages to be counted after the failed message, got %v", bodies)
+	}
+}
+
+func TestSMSPublisherSharedRateLimit(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	// two notifiers texting the on-call number, one of them also a second number
+	messages, server := newFakeTwilio(t)
+	limiters := &Limiters{}
+	newPublisher := func(recipients ...string) *SMSPublisher {
+		p, err := NewSMSPublisher(Config{
+			BaseURL:     server.URL,
+			AccountSID:  "AC123",
+			AuthToken:   "secret",
+			From:        "+15557654321",
+			Recipients:  recipients,
+			MaxMessages: 1,
+			Period:      time.Hour,
+		}, limiters)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	first, second := newPublisher("+15551234567"), newPublisher("+4915112345678", "+15551234567")
+
+	for _, p := range []*SMSPublisher{first, second} {
+		if err := p.Send(context.Background(), publisher.Notification{Message: "3 events"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(*messages) != 2 || (*messages)[0].to != "+15551234567" || (*messages)[1].to != "+4915112345678" {
+		t.Fatalf("expected the shared number to be limited across publishers, got %v", *messages)
+	}
+
+	// a publisher recreated after a change of the recipients keeps the limit of the number
+	clock = clock.Add(time.Hour)
+	if err := newPublisher("+15551234567").Send(context.Background(), publisher.Notification{Message: "3 events"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*messages) != 3 || (*messages)[2].body != "(+1 dropped) 3 events" {
+		t.Errorf("expected the message dropped by the other publisher to be counted, got %v", *messages)
+	}
+}
+
+func TestFit(t *testing.T) {
+	for name, test := range map[string]struct {
+		text   string
+		length int
+		suffix string
+	}{
+		"short":              {text: "BackOff Pod shop/cart-0", length: 23, suffix: "cart-0"},
+		"gsm7":               {text: strings.Repeat("a", 200), length: 160, suffix: "a..."},
+		"gsm7 extension":     {text: strings.Repeat("{", 100), length: 81, suffix: "{..."},
+		"non-gsm7 character": {text: "ü" + strings.Repeat("ł", 100), length: 70, suffix: "ł..."},
+	} {
+		t.Run(name, func(t *testing.T) {
+			fitted := Fit(test.text)
+			if n := len([]rune(fitted)); n != test.length || !strings.HasSuffix(fitted, test.suffix) {
+				t.Errorf("expected %d characters ending in %q, got %d in %q", test.length, test.suffix, n, fitted)
+			}
+		})
+	}
+
+	if _, err := NewSMSPublisher(Config{AccountSID: "AC123", From: "+15557654321", Recipients: []string{"0151 1234"}}, nil); err == nil {
+		t.Error("expected recipients not in E.164 form to be rejected")
+	}
+}
@@ -0,0 +1,172 @@
+package splunk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/notifier/pkg/publisher"
+	"github.com/example/notifier/pkg/publisher/batch"
+)
+
+const (
+	DefaultSourceType = "kube:event"
+	DefaultSource     = "k8s-event-notifier"
+
+	collectorPath = "/services/collector/event"
+	// codeServerBusy is the HEC status code of a full queue, retried like HTTP 503
+	codeServerBusy = 9
+)
+
+var now = time.Now
+
+// Config defines the HTTP Event Collector and how events are indexed
+type Config struct {
+	// URL of the collector, such as https://splunk:8088
+	URL        string
+	Token      string
+	Index      string
+	Source     string
+	SourceType string
+	TLS        *publisher.TLSConfig
+	// BatchSize is the number of events sent per request
+	BatchSize int
+	// FlushInterval is the longest an event waits for its batch to fill up
+	FlushInterval time.Duration
+}
+
+// hecEvent is an event in the HEC JSON format
+type hecEvent struct {
+	Time       float64           `json:"time"`
+	Host       string            `json:"host,omitempty"`
+	Source     string            `json:"source,omitempty"`
+	SourceType string            `json:"sourcetype,omitempty"`
+	Index      string            `json:"index,omitempty"`
+	Event      publisher.Record  `json:"event"`
+	Fields     map[string]string `json:"fields,omitempty"`
+}
+
+// hecResponse is the body HEC answers with, InvalidEvent is the index of the
+// first event it could not parse in the batch
+type hecResponse struct {
+	Text         string `json:"text"`
+	Code         int    `json:"code"`
+	InvalidEvent *int   `json:"invalid-event-number"`
+}
+
+// HECPublisher sends notifications to Splunk in batches. Delivery failures are
+// reported to the error handler.
+type HECPublisher struct {
+	config  Config
+	client  *http.Client
+	batcher *batch.Batcher[hecEvent]
+}
+
+func NewHECPublisher(config Config, onError func(error)) (*HECPublisher, error) {
+	if config.Source == "" {
+		config.Source = DefaultSource
+	}
+	if config.SourceType == "" {
+		config.SourceType = DefaultSourceType
+	}
+	client, err := config.TLS.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	h := &HECPublisher{config: config, client: client}
+	h.batcher = batch.New(config.BatchSize, config.FlushInterval, h.flush, onError)
+	return h, nil
+}
+
+func (h *HECPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	if err := h.batcher.Add(ctx, h.buildEvent(notification)); err != nil {
+		return fmt.Errorf("failed to queue splunk event: %w", err)
+	}
+	return nil
+}
+
+// Close sends the queued events
+func (h *HECPublisher) Close() error {
+	return h.batcher.Close()
+}
+
+func (h *HECPublisher) buildEvent(notification publisher.Notification) hecEvent {
+	event := hecEvent{
+		Time:       float64(now().UnixMilli()) / 1000,
+		Host:       notification.ClusterName,
+		Source:     h.config.Source,
+		SourceType: h.config.SourceType,
+		Index:      h.config.Index,
+		Event:      publisher.NewRecord(notification),
+	}
+
+	if e := notification.Event; e != nil {
+		event.Time = float64(publisher.EventTime(e, now()).UnixMilli()) / 1000
+		namespace := e.InvolvedObject.Namespace
+		if namespace == "" {
+			namespace = e.Namespace
+		}
+		// indexed fields, searchable without extracting them from the event
+		event.Fields = map[string]string{}
+		for key, value := range map[string]string{
+			"namespace": namespace,
+			"kind":      e.InvolvedObject.Kind,
+			"name":      e.InvolvedObject.Name,
+			"reason":    e.Reason,
+			"type":      e.Type,
+			"owner":     notification.Owner,
+		} {
+			if value != "" {
+				event.Fields[key] = value
+			}
+		}
+	}
+	return event
+}
+
+// flush posts the events, returning those worth sending again. HEC indexes the
+// events before the first one it cannot parse and drops the rest of the batch.
+func (h *HECPublisher) flush(ctx context.Context, events []hecEvent) ([]hecEvent, error) {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(h.config.URL, "/")+collectorPath, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Splunk "+h.config.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	_, err = publisher.DoWith(h.client, req)
+	if err == nil {
+		return nil, nil
+	}
+
+	var httpErr *publisher.HTTPError
+	if !errors.As(err, &httpErr) {
+		return events, fmt.Errorf("failed to send %d events to splunk: %w", len(events), err)
+	}
+	var response hecResponse
+	_ = json.Unmarshal(httpErr.Body, &response)
+
+	switch {
+	case httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= 500 || response.Code == codeServerBusy:
+		return events, fmt.Errorf("failed to send %d events to splunk: %w", len(events), err)
+	case response.InvalidEvent != nil && *response.InvalidEvent >= 0 && *response.InvalidEvent < len(events):
+		invalid := *response.InvalidEvent
+		return events[invalid+1:], fmt.Errorf("splunk rejected event %d of %d: %s", invalid, len(events), response.Text)
+	default:
+		return nil, fmt.Errorf("splunk rejected %d events: %w", len(events), err)
+	}
+}
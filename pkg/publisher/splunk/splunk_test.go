@@ -0,0 +1,136 @@
+package splunk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func backOffEvent(name string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop", UID: "e1"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: name},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Type:           corev1.EventTypeWarning,
+		LastTimestamp:  metav1.NewTime(time.Date(2024, 5, 1, 12, 30, 0, 500_000_000, time.UTC)),
+	}
+}
+
+func TestHECPublisherSend(t *testing.T) {
+	requests := make(chan []hecEvent, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != collectorPath || r.Header.Get("Authorization") != "Splunk token" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		var events []hecEvent
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var event hecEvent
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				t.Error(err)
+			}
+			events = append(events, event)
+		}
+		requests <- events
+		_, _ = w.Write([]byte(`{"text":"Success","code":0}`))
+	}))
+	defer server.Close()
+
+	p, err := NewHECPublisher(Config{URL: server.URL + "/", Token: "token", Index: "k8s", BatchSize: 2, FlushInterval: time.Hour}, func(err error) {
+		t.Errorf("unexpected error: %v", err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := p.Send(ctx, publisher.Notification{Event: backOffEvent("cart-0"), ClusterName: "prod", Owner: "StatefulSet/cart"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(ctx, publisher.Notification{Event: backOffEvent("cart-1"), ClusterName: "prod"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(ctx, publisher.Notification{Message: "3 events", ClusterName: "prod"}); err != nil {
+		t.Fatal(err)
+	}
+	// the digest waits for the batch to fill up or the publisher to close
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	batch := <-requests
+	if len(batch) != 2 {
+		t.Fatalf("expected a batch of 2 events, got %d", len(batch))
+	}
+	event := batch[0]
+	if event.Time != 1714566600.5 || event.Host != "prod" || event.Index != "k8s" || event.SourceType != DefaultSourceType {
+		t.Errorf("unexpected event metadata %+v", event)
+	}
+	if event.Fields["namespace"] != "shop" || event.Fields["name"] != "cart-0" || event.Fields["owner"] != "StatefulSet/cart" {
+		t.Errorf("unexpected indexed fields %v", event.Fields)
+	}
+	if event.Event.Event == nil || event.Event.Event.Reason != "BackOff" {
+		t.Errorf("unexpected event %+v", event.Event)
+	}
+	if _, ok := batch[1].Fields["owner"]; ok {
+		t.Errorf("expected empty fields to be left out, got %v", batch[1].Fields)
+	}
+
+	digest := <-requests
+	if len(digest) != 1 || digest[0].Event.Message != "3 events" || digest[0].Fields != nil {
+		t.Errorf("unexpected digest %+v", digest)
+	}
+}
+
+func TestHECPublisherPartialFailure(t *testing.T) {
+	status, body := 0, ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	p, err := NewHECPublisher(Config{URL: server.URL, Token: "token"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = p.Close() }()
+
+	events := []hecEvent{
+		p.buildEvent(publisher.Notification{Event: backOffEvent("cart-0")}),
+		p.buildEvent(publisher.Notification{Event: backOffEvent("cart-1")}),
+		p.buildEvent(publisher.Notification{Event: backOffEvent("cart-2")}),
+	}
+
+	for _, tc := range []struct {
+		name   string
+		status int
+		body   string
+		retry  int
+	}{
+		{"invalid event", http.StatusBadRequest, `{"text":"Invalid data format","code":6,"invalid-event-number":1}`, 1},
+		{"server busy", http.StatusServiceUnavailable, `{"text":"Server is busy","code":9}`, 3},
+		{"invalid token", http.StatusForbidden, `{"text":"Invalid token","code":4}`, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body = tc.status, tc.body
+			retry, err := p.flush(context.Background(), events)
+			if err == nil {
+				t.Error("expected an error")
+			}
+			if len(retry) != tc.retry {
+				t.Errorf("expected %d events to be retried, got %d", tc.retry, len(retry))
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package syslog
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+// Transports
+const (
+	UDP = "udp"
+	TCP = "tcp"
+	TLS = "tls"
+)
+
+// Severities, RFC 5424 section 6.2.1
+const (
+	SeverityWarning       = 4
+	SeverityNotice        = 5
+	SeverityInformational = 6
+)
+
+const (
+	// DefaultFacility is local0
+	DefaultFacility = 16
+	// DefaultStructuredDataID uses the enterprise number reserved for documentation
+	// by RFC 5612, organisations with their own number should set it
+	DefaultStructuredDataID = "k8s@32473"
+	// MaxUDPSize is the datagram size receivers should accept, RFC 5426 section 3.2
+	MaxUDPSize = 2048
+
+	appName     = "k8s-event-notifier"
+	dialTimeout = 10 * time.Second
+	// bom marks the message as UTF-8, RFC 5424 section 6.4
+	bom = "\ufeff"
+)
+
+var now = time.Now
+
+// SenderConfig defines the receiver
+type SenderConfig struct {
+	Transport string
+	// Address of the receiver in host:port form
+	Address string
+	// TLS settings for the tls transport
+	TLS *publisher.TLSConfig
+}
+
+// Sender holds the connection to the receiver, dialing again after write
+// errors. It is shared by the publishers with the same settings.
+type Sender struct {
+	mu        sync.Mutex
+	transport string
+	address   string
+	tlsConfig *tls.Config
+	conn      net.Conn
+}
+
+func NewSender(config SenderConfig) (*Sender, error) {
+	s := &Sender{transport: config.Transport, address: config.Address}
+	switch config.Transport {
+	case UDP, TCP:
+	case TLS:
+		tlsConfig := config.TLS
+		if tlsConfig == nil {
+			tlsConfig = &publisher.TLSConfig{}
+		}
+		var err error
+		if s.tlsConfig, err = tlsConfig.Build(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported syslog transport %q", config.Transport)
+	}
+	return s, nil
+}
+
+// send writes one message, framed by octet counting on stream transports (RFC 6587 section 3.4.1)
+func (s *Sender) send(ctx context.Context, message []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	frame := message
+	if s.transport != UDP {
+		frame = append([]byte(strconv.Itoa(len(message))+" "), message...)
+	}
+
+	err := s.write(ctx, frame)
+	if err != nil && s.transport != UDP && ctx.Err() == nil {
+		// the receiver may have closed an idle connection, try once on a new one
+		err = s.write(ctx, frame)
+	}
+	return err
+}
+
+func (s *Sender) write(ctx context.Context, frame []byte) error {
+	if s.conn == nil {
+		conn, err := s.dial(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog receiver %s: %w", s.address, err)
+		}
+		s.conn = conn
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(dialTimeout)
+	}
+	_ = s.conn.SetWriteDeadline(deadline)
+	if _, err := s.conn.Write(frame); err != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (s *Sender) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	if s.transport == TLS {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: s.tlsConfig}
+		return tlsDialer.DialContext(ctx, "tcp", s.address)
+	}
+	return dialer.DialContext(ctx, s.transport, s.address)
+}
+
+func (s *Sender) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// SyslogPublisher sends notifications as RFC 5424 messages
+type SyslogPublisher struct {
+	Sender           *Sender
+	Facility         int
+	StructuredDataID string
+}
+
+func NewSyslogPublisher(sender *Sender, facility int, structuredDataID string) (*SyslogPublisher, error) {
+	if facility < 0 || facility > 23 {
+		return nil, fmt.Errorf("invalid syslog facility %d", facility)
+	}
+	if structuredDataID == "" {
+		structuredDataID = DefaultStructuredDataID
+	}
+	if !validName(structuredDataID, 32) {
+		return nil, fmt.Errorf("invalid structured data ID %q", structuredDataID)
+	}
+	return &SyslogPublisher{Sender: sender, Facility: facility, StructuredDataID: structuredDataID}, nil
+}
+
+func (s *SyslogPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	message := s.format(notification)
+	if s.Sender.transport == UDP && len(message) > MaxUDPSize {
+		message = truncateBytes(message, MaxUDPSize)
+	}
+	if err := s.Sender.send(ctx, message); err != nil {
+		return fmt.Errorf("failed to send syslog message: %w", err)
+	}
+	return nil
+}
+
+// format renders the message as
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (s *SyslogPublisher) format(notification publisher.Notification) []byte {
+	severity := SeverityNotice
+	timestamp := now()
+	msgID := "digest"
+	structuredData := "-"
+	text := notification.Message
+
+	if event := notification.Event; event != nil {
+		severity = SeverityInformational
+		if event.Type == corev1.EventTypeWarning {
+			severity = SeverityWarning
+		}
+		timestamp = publisher.EventTime(event, timestamp)
+		msgID = header(event.Reason, 32)
+		text = event.Message
+
+		namespace := event.InvolvedObject.Namespace
+		if namespace == "" {
+			namespace = event.Namespace
+		}
+		params := [][2]string{
+			{"namespace", namespace},
+			{"kind", event.InvolvedObject.Kind},
+			{"name", event.InvolvedObject.Name},
+			{"reason", event.Reason},
+			{"type", event.Type},
+			{"owner", notification.Owner},
+			{"cluster", notification.ClusterName},
+		}
+		var b strings.Builder
+		b.WriteString("[" + s.StructuredDataID)
+		for _, param := range params {
+			if param[1] != "" {
+				b.WriteString(" " + param[0] + `="` + escapeParam(param[1]) + `"`)
+			}
+		}
+		b.WriteString("]")
+		structuredData = b.String()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s - %s %s",
+		s.Facility*8+severity,
+		timestamp.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		header(notification.ClusterName, 255),
+		appName,
+		msgID,
+		structuredData,
+	)
+	if text != "" {
+		b.WriteString(" " + bom + text)
+	}
+	return []byte(b.String())
+}
+
+// header makes value a valid header field: printable ASCII without spaces,
+// at most limit long, "-" if empty
+func header(value string, limit int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+	if len(value) > limit {
+		value = value[:limit]
+	}
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func validName(name string, limit int) bool {
+	if name == "" || len(name) > limit {
+		return false
+	}
+	for _, r := range name {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeParam escapes the characters PARAM-VALUE reserves, RFC 5424 section 6.3.3
+func escapeParam(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
+
+// truncateBytes cuts message to at most limit bytes without splitting a character
+func truncateBytes(message []byte, limit int) []byte {
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return message[:cut]
+}
@@ -0,0 +1,200 @@
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func backOffEvent() *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop", UID: "e1"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "cart-7f9c-x2"},
+		Reason:         "BackOff",
+		Message:        `Back-off restarting failed container "cart" [exit 1]`,
+		Type:           corev1.EventTypeWarning,
+		LastTimestamp:  metav1.NewTime(time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)),
+	}
+}
+
+// readFrames reads octet-counted frames from the first connection accepted by listener
+func readFrames(t *testing.T, listener net.Listener) <-chan string {
+	t.Helper()
+	frames := make(chan string, 10)
+	go func() {
+		defer close(frames)
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		reader := bufio.NewReader(conn)
+		for {
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+			if err != nil {
+				t.Errorf("invalid frame length %q", length)
+				return
+			}
+			frame := make([]byte, n)
+			if _, err := io.ReadFull(reader, frame); err != nil {
+				return
+			}
+			frames <- string(frame)
+		}
+	}()
+	return frames
+}
+
+func receive(t *testing.T, frames <-chan string) string {
+	t.Helper()
+	select {
+	case frame := <-frames:
+		return frame
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a message")
+		return ""
+	}
+}
+
+func TestSyslogPublisherTCP(t *testing.T) {
+	now = func() time.Time { return time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listener.Close() }()
+	frames := readFrames(t, listener)
+
+	sender, err := NewSender(SenderConfig{Transport: TCP, Address: listener.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sender.Close() }()
+	p, err := NewSyslogPublisher(sender, DefaultFacility, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notification := publisher.Notification{Event: backOffEvent(), ClusterName: "prod", Owner: "Deployment/cart"}
+	if err := p.Send(context.Background(), notification); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(context.Background(), publisher.Notification{Message: "3 events\nin shop"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<132>1 2024-05-01T12:30:00.000000Z prod k8s-event-notifier - BackOff ` +
+		`[k8s@32473 namespace="shop" kind="Pod" name="cart-7f9c-x2" reason="BackOff" type="Warning" owner="Deployment/cart" cluster="prod"] ` +
+		bom + `Back-off restarting failed container "cart" [exit 1]`
+	if frame := receive(t, frames); frame != expected {
+		t.Errorf("unexpected message\n%s\nexpected\n%s", frame, expected)
+	}
+
+	expected = "<133>1 2024-05-01T13:00:00.000000Z - k8s-event-notifier - digest - " + bom + "3 events\nin shop"
+	if frame := receive(t, frames); frame != expected {
+		t.Errorf("unexpected digest\n%q\nexpected\n%q", frame, expected)
+	}
+}
+
+func TestSyslogPublisherUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	sender, err := NewSender(SenderConfig{Transport: UDP, Address: conn.LocalAddr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sender.Close() }()
+	p, err := NewSyslogPublisher(sender, 3, "event@12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := backOffEvent()
+	event.Type = corev1.EventTypeNormal
+	event.Message = strings.Repeat("é", MaxUDPSize)
+	if err := p.Send(context.Background(), publisher.Notification{Event: event}); err != nil {
+		t.Fatal(err)
+	}
+
+	buffer := make([]byte, 65536)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	datagram := buffer[:n]
+	if !strings.HasPrefix(string(datagram), "<30>1 ") || !strings.Contains(string(datagram), "[event@12345 ") {
+		t.Errorf("unexpected datagram %.80s", datagram)
+	}
+	if n > MaxUDPSize || !utf8.Valid(datagram) {
+		t.Errorf("expected the datagram cut to %d bytes on a character boundary, got %d", MaxUDPSize, n)
+	}
+}
+
+func TestSyslogPublisherTLS(t *testing.T) {
+	// borrow the self-signed certificate httptest issues for 127.0.0.1
+	certServer := httptest.NewUnstartedServer(nil)
+	certServer.StartTLS()
+	defer certServer.Close()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: certServer.TLS.Certificates})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listener.Close() }()
+	frames := readFrames(t, listener)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certServer.Certificate().Raw})
+	sender, err := NewSender(SenderConfig{Transport: TLS, Address: listener.Addr().String(), TLS: &publisher.TLSConfig{CA: string(ca)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sender.Close() }()
+	p, err := NewSyslogPublisher(sender, DefaultFacility, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Send(context.Background(), publisher.Notification{Event: backOffEvent()}); err != nil {
+		t.Fatal(err)
+	}
+	if frame := receive(t, frames); !strings.HasPrefix(frame, "<132>1 ") {
+		t.Errorf("unexpected message %s", frame)
+	}
+}
+
+func TestSyslogInvalidSettings(t *testing.T) {
+	if _, err := NewSender(SenderConfig{Transport: "relp", Address: "127.0.0.1:514"}); err == nil {
+		t.Error("expected an error for an unsupported transport")
+	}
+	if _, err := NewSyslogPublisher(nil, 24, ""); err == nil {
+		t.Error("expected an error for an invalid facility")
+	}
+	if _, err := NewSyslogPublisher(nil, 1, "k8s events"); err == nil {
+		t.Error("expected an error for an invalid structured data ID")
+	}
+}
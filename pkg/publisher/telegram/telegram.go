@@ -0,0 +1,185 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+const DefaultAPIURL = "https://api.telegram.org"
+
+// maxText is the sendMessage text limit, counted in UTF-16 code units by Telegram
+const maxText = 4096
+
+// specialCharacters must be escaped anywhere outside of entities in MarkdownV2,
+// see https://core.telegram.org/bots/api#markdownv2-style
+const specialCharacters = "_*[]()~`>#+-=|{}.!\\"
+
+type Message struct {
+	ChatID             string              `json:"chat_id"`
+	Text               string              `json:"text"`
+	ParseMode          string              `json:"parse_mode"`
+	LinkPreviewOptions *LinkPreviewOptions `json:"link_preview_options,omitempty"`
+}
+
+type LinkPreviewOptions struct {
+	IsDisabled bool `json:"is_disabled"`
+}
+
+// response is the envelope of every Bot API answer
+type response struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
+// TelegramPublisher sends notifications to a chat through the Bot API
+type TelegramPublisher struct {
+	APIURL string
+	Token  string
+	ChatID string
+}
+
+func NewTelegramPublisher(token, chatID string) *TelegramPublisher {
+	return &TelegramPublisher{APIURL: DefaultAPIURL, Token: token, ChatID: chatID}
+}
+
+func (t *TelegramPublisher) Send(ctx context.Context, notification publisher.Notification) error {
+	message := Message{
+		ChatID:             t.ChatID,
+		Text:               buildText(notification),
+		ParseMode:          "MarkdownV2",
+		LinkPreviewOptions: &LinkPreviewOptions{IsDisabled: true},
+	}
+
+	endpoint := strings.TrimSuffix(t.APIURL, "/") + "/bot" + t.Token + "/sendMessage"
+	body, err := publisher.PostJSON(ctx, endpoint, message, nil)
+	if err != nil {
+		// the request URL contains the bot token, keep it out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		var httpErr *publisher.HTTPError
+		if errors.As(err, &httpErr) {
+			if description := errorDescription(httpErr.Body); description != "" {
+				return fmt.Errorf("failed to send telegram message: %s (status: %d)", description, httpErr.StatusCode)
+			}
+		}
+		return fmt.Errorf("failed to send telegram message: %w", err)
+	}
+
+	var result response
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("failed to decode telegram response: %w", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("failed to send telegram message: %s", result.Description)
+	}
+
+	return nil
+}
+
+// buildText renders the notification as MarkdownV2 within the message limit
+func buildText(notification publisher.Notification) string {
+	event := notification.Event
+	if event == nil {
+		header := ""
+		if notification.ClusterName != "" {
+			header = "*" + Escape(notification.ClusterName) + "*\n"
+		}
+		return header + escapeLimit(notification.Message, maxText-length(header))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s *%s*: %s `%s`\n", emoji(event.Type), Escape(event.Reason), Escape(event.InvolvedObject.Kind), escapeCode(event.InvolvedObject.Name))
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "*%s:* %s\n", name, Escape(value))
+		}
+	}
+	field("Namespace", event.Namespace)
+	field("Type", event.Type)
+	if event.Count > 1 {
+		field("Count", strconv.Itoa(int(event.Count)))
+	}
+	field("Cluster", notification.ClusterName)
+	b.WriteString("\n")
+
+	header := b.String()
+	return header + escapeLimit(event.Message, maxText-length(header))
+}
+
+// Escape escapes text for use outside of entities in MarkdownV2
+func Escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune(specialCharacters, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// escapeCode escapes text for use inside a code entity, where only ` and \ are special
+func escapeCode(text string) string {
+	return strings.NewReplacer("\\", "\\\\", "`", "\\`").Replace(text)
+}
+
+// escapeLimit escapes text and truncates it so the escaped result fits in limit
+// UTF-16 code units, never splitting an escape sequence
+func escapeLimit(text string, limit int) string {
+	escaped := Escape(text)
+	if length(escaped) <= limit {
+		return escaped
+	}
+
+	const ellipsis = "…"
+	budget := limit - length(ellipsis)
+	var b strings.Builder
+	for _, r := range text {
+		next := string(r)
+		if strings.ContainsRune(specialCharacters, r) {
+			next = "\\" + next
+		}
+		if budget -= length(next); budget < 0 {
+			break
+		}
+		b.WriteString(next)
+	}
+	return b.String() + ellipsis
+}
+
+// length counts text in UTF-16 code units, the way Telegram measures messages
+func length(text string) int {
+	n := 0
+	for _, r := range text {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+func emoji(eventType string) string {
+	if eventType == corev1.EventTypeWarning {
+		return "⚠️"
+	}
+	return "ℹ️"
+}
+
+func errorDescription(body []byte) string {
+	var result response
+	if err := json.Unmarshal(body, &result); err != nil {
+		return ""
+	}
+	return result.Description
+}
@@ -0,0 +1,116 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/notifier/pkg/publisher"
+)
+
+func warningEvent() *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "kube_system"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "kube_system", Name: "my_app-0"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container (exit code 1).",
+		Type:           corev1.EventTypeWarning,
+		Count:          2,
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := map[string]string{
+		"my_app-0":          `my\_app\-0`,
+		"a*b[c](d)~e`f>g#h": "a\\*b\\[c\\]\\(d\\)\\~e\\`f\\>g\\#h",
+		"+=|{}.!":           `\+\=\|\{\}\.\!`,
+		`C:\tmp`:            `C:\\tmp`,
+		"plain text":        "plain text",
+	}
+	for in, want := range tests {
+		if got := Escape(in); got != want {
+			t.Errorf("Escape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTelegramPublisherSend(t *testing.T) {
+	var message Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bot123:secret/sendMessage" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("failed to decode message: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"ok": true, "result": {"message_id": 1}}`))
+	}))
+	defer server.Close()
+
+	p := NewTelegramPublisher("123:secret", "-100200300")
+	p.APIURL = server.URL
+	if err := p.Send(context.Background(), publisher.Notification{Event: warningEvent(), ClusterName: "eu-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if message.ChatID != "-100200300" || message.ParseMode != "MarkdownV2" {
+		t.Errorf("unexpected message %+v", message)
+	}
+	want := "⚠️ *BackOff*: Pod `my_app-0`\n" +
+		"*Namespace:* kube\\_system\n" +
+		"*Type:* Warning\n" +
+		"*Count:* 2\n" +
+		"*Cluster:* eu\\-1\n" +
+		"\n" +
+		"Back\\-off restarting failed container \\(exit code 1\\)\\."
+	if message.Text != want {
+		t.Errorf("unexpected text:\n%s\nwant:\n%s", message.Text, want)
+	}
+}
+
+func TestBuildTextLimit(t *testing.T) {
+	event := warningEvent()
+	// every character needs escaping, doubling the escaped length
+	event.Message = strings.Repeat("_", 5000)
+
+	text := buildText(publisher.Notification{Event: event})
+	if n := length(text); n > maxText {
+		t.Fatalf("text is %d characters long, limit is %d", n, maxText)
+	}
+	if !strings.HasSuffix(text, "\\_…") {
+		t.Errorf("expected the cut to keep escape sequences intact, got suffix %q", text[len(text)-10:])
+	}
+
+	// characters outside the BMP count twice
+	text = buildText(publisher.Notification{Message: strings.Repeat("🔥", 3000)})
+	if n := length(text); n > maxText {
+		t.Errorf("text is %d UTF-16 units long, limit is %d", n, maxText)
+	}
+}
+
+func TestTelegramPublisherError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"ok": false, "error_code": 400, "description": "Bad Request: chat not found"}`))
+	}))
+	defer server.Close()
+
+	p := NewTelegramPublisher("123:secret", "42")
+	p.APIURL = server.URL
+	err := p.Send(context.Background(), publisher.Notification{Message: "digest"})
+	if err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Errorf("expected the Bot API description in the error, got %v", err)
+	}
+
+	server.Close()
+	err = p.Send(context.Background(), publisher.Notification{Message: "digest"})
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("expected a connection error without the bot token, got %v", err)
+	}
+}
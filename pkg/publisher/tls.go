@@ -0,0 +1,62 @@
+package publisher
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// TLSConfig holds PEM encoded TLS material, as read from Secrets
+type TLSConfig struct {
+	// CA verifies the server certificate, the system roots are used if empty
+	CA string
+	// Cert and Key authenticate the client, both or neither must be set
+	Cert               string
+	Key                string
+	InsecureSkipVerify bool
+}
+
+// Build parses the PEM material into a tls.Config
+func (c *TLSConfig) Build() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CA != "" {
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM([]byte(c.CA)) {
+			return nil, errors.New("no certificates found in CA PEM")
+		}
+		config.RootCAs = roots
+	}
+
+	if (c.Cert == "") != (c.Key == "") {
+		return nil, errors.New("client certificate and key must be set together")
+	}
+	if c.Cert != "" {
+		certificate, err := tls.X509KeyPair([]byte(c.Cert), []byte(c.Key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
+
+// HTTPClient returns a client using the TLS settings, the default client if c is nil
+func (c *TLSConfig) HTTPClient() (*http.Client, error) {
+	if c == nil {
+		return http.DefaultClient, nil
+	}
+	config, err := c.Build()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return &http.Client{Transport: transport}, nil
+}
@@ -1,7 +1,84 @@
 package publisher
 
-import "context"
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Notification is a message to publish, along with the event it was built from
+type Notification struct {
+	// Message is the rendered notification text
+	Message string
+	// Event that triggered the notification, nil for summaries such as digests
+	Event *corev1.Event
+	// ClusterName identifies the cluster the event comes from, if configured
+	ClusterName string
+	// Owner is the top-level controller of the event object in kind/name form
+	// (e.g., Deployment/web), empty for summaries
+	Owner string
+}
 
 type Publisher interface {
-	Send(ctx context.Context, message string) error
+	Send(ctx context.Context, notification Notification) error
+}
+
+// Resolver is implemented by publishers that can close what they opened for
+// an object once an event signals that the condition cleared
+type Resolver interface {
+	Resolve(ctx context.Context, notification Notification) error
+}
+
+// ObjectKey identifies the object an event is about in namespace/kind/name form
+func ObjectKey(event *corev1.Event) string {
+	namespace := event.InvolvedObject.Namespace
+	if namespace == "" {
+		namespace = event.Namespace
+	}
+	return namespace + "/" + event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name
+}
+
+// Fingerprint identifies a recurring condition: events of the same reason for the
+// same owner, namespace and cluster share it. Digests have no fingerprint.
+func Fingerprint(notification Notification) string {
+	if notification.Event == nil {
+		return ""
+	}
+	return fingerprint(notification, notification.Event.Reason)
+}
+
+// OwnerFingerprint identifies the owner of the event object within its namespace
+// and cluster, whatever the reason of the event
+func OwnerFingerprint(notification Notification) string {
+	if notification.Event == nil {
+		return ""
+	}
+	return fingerprint(notification)
+}
+
+func fingerprint(notification Notification, extra ...string) string {
+	event := notification.Event
+	namespace := event.InvolvedObject.Namespace
+	if namespace == "" {
+		namespace = event.Namespace
+	}
+	owner := notification.Owner
+	if owner == "" {
+		owner = event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name
+	}
+	key := strings.Join(append([]string{notification.ClusterName, namespace, owner}, extra...), "/")
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Truncate shortens text to at most limit characters, marking the cut with an ellipsis
+func Truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
 }